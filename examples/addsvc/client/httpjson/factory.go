@@ -33,6 +33,7 @@ func MakeSumEndpointFactory(tracer opentracing.Tracer, tracingLogger log.Logger)
 			server.DecodeSumResponse,
 			httptransport.SetClient(nil),
 			httptransport.SetClientBefore(kitot.ToHTTPRequest(tracer, tracingLogger)),
+			httptransport.ClientAfter(kitot.FromHTTPResponse()),
 		)
 
 		return client.Endpoint(), nil, nil
@@ -58,6 +59,7 @@ func MakeConcatEndpointFactory(tracer opentracing.Tracer, tracingLogger log.Logg
 			server.DecodeConcatResponse,
 			httptransport.SetClient(nil),
 			httptransport.SetClientBefore(kitot.ToHTTPRequest(tracer, tracingLogger)),
+			httptransport.ClientAfter(kitot.FromHTTPResponse()),
 		)
 
 		return client.Endpoint(), nil, nil
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/examples/addsvc/server"
+	netrpctransport "github.com/go-kit/kit/transport/netrpc"
 )
 
 // SumEndpointFactory transforms host:port strings into Endpoints.
@@ -16,14 +17,13 @@ func SumEndpointFactory(instance string) (endpoint.Endpoint, io.Closer, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		var reply server.SumResponse
-		if err := client.Call("addsvc.Sum", request.(server.SumRequest), &reply); err != nil {
-			return server.SumResponse{}, err
-		}
-		return reply, nil
-	}, client, nil
+	return netrpctransport.NewClient(
+		client,
+		"addsvc.Sum",
+		encodeRequest,
+		decodeSumResponse,
+		server.SumResponse{},
+	).Endpoint(), client, nil
 }
 
 // ConcatEndpointFactory transforms host:port strings into Endpoints.
@@ -32,12 +32,24 @@ func ConcatEndpointFactory(instance string) (endpoint.Endpoint, io.Closer, error
 	if err != nil {
 		return nil, nil, err
 	}
+	return netrpctransport.NewClient(
+		client,
+		"addsvc.Concat",
+		encodeRequest,
+		decodeConcatResponse,
+		server.ConcatResponse{},
+	).Endpoint(), client, nil
+}
+
+// net/rpc requests are the business domain requests themselves.
+func encodeRequest(_ context.Context, request interface{}) (interface{}, error) {
+	return request, nil
+}
+
+func decodeSumResponse(_ context.Context, reply interface{}) (interface{}, error) {
+	return *reply.(*server.SumResponse), nil
+}
 
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		var reply server.ConcatResponse
-		if err := client.Call("addsvc.Concat", request.(server.ConcatRequest), &reply); err != nil {
-			return server.ConcatResponse{}, err
-		}
-		return reply, nil
-	}, client, nil
+func decodeConcatResponse(_ context.Context, reply interface{}) (interface{}, error) {
+	return *reply.(*server.ConcatResponse), nil
 }
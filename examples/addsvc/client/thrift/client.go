@@ -8,6 +8,7 @@ import (
 	"github.com/go-kit/kit/examples/addsvc/server"
 	thriftadd "github.com/go-kit/kit/examples/addsvc/thrift/gen-go/add"
 	"github.com/go-kit/kit/log"
+	thrifttransport "github.com/go-kit/kit/transport/thrift"
 	"golang.org/x/net/context"
 )
 
@@ -46,33 +47,39 @@ type client struct {
 	log.Logger
 }
 
-// SumEndpointFactory transforms host:port strings into Endpoints.
+// SumEndpoint transforms host:port strings into Endpoints.
 func (c client) SumEndpoint(instance string) (endpoint.Endpoint, io.Closer, error) {
-	transportSocket, err := thrift.NewTSocket(instance)
+	cli, trans, err := c.dial(instance)
 	if err != nil {
-		c.Logger.Log("during", "thrift.NewTSocket", "err", err)
 		return nil, nil, err
 	}
-	trans := c.TTransportFactory.GetTransport(transportSocket)
+	return thrifttransport.NewClient(
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			args := request.(*thriftadd.AddServiceSumArgs)
+			return cli.Sum(args.A, args.B)
+		},
+		encodeSumRequest,
+		decodeSumResponse,
+	).Endpoint(), trans, nil
+}
 
-	if err := trans.Open(); err != nil {
-		c.Logger.Log("during", "thrift transport.Open", "err", err)
+// ConcatEndpoint transforms host:port strings into Endpoints.
+func (c client) ConcatEndpoint(instance string) (endpoint.Endpoint, io.Closer, error) {
+	cli, trans, err := c.dial(instance)
+	if err != nil {
 		return nil, nil, err
 	}
-	cli := thriftadd.NewAddServiceClientFactory(trans, c.TProtocolFactory)
-
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		sumRequest := request.(server.SumRequest)
-		reply, err := cli.Sum(int64(sumRequest.A), int64(sumRequest.B))
-		if err != nil {
-			return server.SumResponse{}, err
-		}
-		return server.SumResponse{V: int(reply.Value)}, nil
-	}, trans, nil
+	return thrifttransport.NewClient(
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			args := request.(*thriftadd.AddServiceConcatArgs)
+			return cli.Concat(args.A, args.B)
+		},
+		encodeConcatRequest,
+		decodeConcatResponse,
+	).Endpoint(), trans, nil
 }
 
-// ConcatEndpointFactory transforms host:port strings into Endpoints.
-func (c client) ConcatEndpoint(instance string) (endpoint.Endpoint, io.Closer, error) {
+func (c client) dial(instance string) (*thriftadd.AddServiceClient, thrift.TTransport, error) {
 	transportSocket, err := thrift.NewTSocket(instance)
 	if err != nil {
 		c.Logger.Log("during", "thrift.NewTSocket", "err", err)
@@ -84,14 +91,25 @@ func (c client) ConcatEndpoint(instance string) (endpoint.Endpoint, io.Closer, e
 		c.Logger.Log("during", "thrift transport.Open", "err", err)
 		return nil, nil, err
 	}
-	cli := thriftadd.NewAddServiceClientFactory(trans, c.TProtocolFactory)
+	return thriftadd.NewAddServiceClientFactory(trans, c.TProtocolFactory), trans, nil
+}
+
+func encodeSumRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(server.SumRequest)
+	return &thriftadd.AddServiceSumArgs{A: int64(req.A), B: int64(req.B)}, nil
+}
+
+func decodeSumResponse(_ context.Context, response interface{}) (interface{}, error) {
+	reply := response.(*thriftadd.SumReply)
+	return server.SumResponse{V: int(reply.Value)}, nil
+}
+
+func encodeConcatRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(server.ConcatRequest)
+	return &thriftadd.AddServiceConcatArgs{A: req.A, B: req.B}, nil
+}
 
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		concatRequest := request.(server.ConcatRequest)
-		reply, err := cli.Concat(concatRequest.A, concatRequest.B)
-		if err != nil {
-			return server.ConcatResponse{}, err
-		}
-		return server.ConcatResponse{V: reply.Value}, nil
-	}, trans, nil
+func decodeConcatResponse(_ context.Context, response interface{}) (interface{}, error) {
+	reply := response.(*thriftadd.ConcatReply)
+	return server.ConcatResponse{V: reply.Value}, nil
 }
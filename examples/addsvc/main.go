@@ -161,7 +161,8 @@ func main() {
 			server.DecodeSumRequest,
 			server.EncodeSumResponse,
 			httptransport.ServerErrorLogger(transportLogger),
-			httptransport.ServerBefore(kitot.FromHTTPRequest(tracer, "sum", tracingLogger)),
+			httptransport.ServerBefore(kitot.StartHTTPServerSpan(tracer, "sum", tracingLogger)),
+			httptransport.ServerFinalizer(kitot.FinishHTTPServerSpan()),
 		))
 
 		concat = makeConcatEndpoint(svc)
@@ -172,7 +173,8 @@ func main() {
 			server.DecodeConcatRequest,
 			server.EncodeConcatResponse,
 			httptransport.ServerErrorLogger(transportLogger),
-			httptransport.ServerBefore(kitot.FromHTTPRequest(tracer, "concat", tracingLogger)),
+			httptransport.ServerBefore(kitot.StartHTTPServerSpan(tracer, "concat", tracingLogger)),
+			httptransport.ServerFinalizer(kitot.FinishHTTPServerSpan()),
 		))
 
 		transportLogger.Log("addr", *httpAddr)
@@ -198,7 +200,7 @@ func main() {
 	go func() {
 		transportLogger := log.NewContext(logger).With("transport", "net/rpc")
 		s := rpc.NewServer()
-		if err := s.RegisterName("addsvc", netrpcBinding{svc}); err != nil {
+		if err := s.RegisterName("addsvc", newNetRPCBinding(root, svc, transportLogger)); err != nil {
 			errc <- err
 			return
 		}
@@ -240,7 +242,7 @@ func main() {
 		transportLogger := log.NewContext(logger).With("transport", "thrift")
 		transportLogger.Log("addr", *thriftAddr)
 		errc <- thrift.NewTSimpleServer4(
-			thriftadd.NewAddServiceProcessor(thriftBinding{svc}),
+			thriftadd.NewAddServiceProcessor(newThriftBinding(root, svc, transportLogger)),
 			transport,
 			transportFactory,
 			protocolFactory,
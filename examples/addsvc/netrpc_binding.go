@@ -1,21 +1,65 @@
 package main
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/go-kit/kit/examples/addsvc/server"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport/netrpc"
 )
 
 type netrpcBinding struct {
-	server.AddService
+	sum, concat netrpc.Handler
+}
+
+func newNetRPCBinding(ctx context.Context, svc server.AddService, logger log.Logger) netrpcBinding {
+	return netrpcBinding{
+		sum: netrpc.NewServer(
+			ctx,
+			makeSumEndpoint(svc),
+			decodeNetRPCSumRequest,
+			encodeNetRPCResponse,
+			netrpc.ServerErrorLogger(logger),
+		),
+		concat: netrpc.NewServer(
+			ctx,
+			makeConcatEndpoint(svc),
+			decodeNetRPCConcatRequest,
+			encodeNetRPCResponse,
+			netrpc.ServerErrorLogger(logger),
+		),
+	}
 }
 
 func (b netrpcBinding) Sum(request server.SumRequest, response *server.SumResponse) error {
-	v := b.AddService.Sum(request.A, request.B)
-	(*response) = server.SumResponse{V: v}
+	resp, err := b.sum.ServeNetRPC(request)
+	if err != nil {
+		return err
+	}
+	*response = resp.(server.SumResponse)
 	return nil
 }
 
 func (b netrpcBinding) Concat(request server.ConcatRequest, response *server.ConcatResponse) error {
-	v := b.AddService.Concat(request.A, request.B)
-	(*response) = server.ConcatResponse{V: v}
+	resp, err := b.concat.ServeNetRPC(request)
+	if err != nil {
+		return err
+	}
+	*response = resp.(server.ConcatResponse)
 	return nil
 }
+
+func decodeNetRPCSumRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(server.SumRequest)
+	return &req, nil
+}
+
+func decodeNetRPCConcatRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(server.ConcatRequest)
+	return &req, nil
+}
+
+// net/rpc replies are the business domain responses themselves.
+func encodeNetRPCResponse(_ context.Context, response interface{}) (interface{}, error) {
+	return response, nil
+}
@@ -1,20 +1,69 @@
 package main
 
 import (
+	"golang.org/x/net/context"
+
 	"github.com/go-kit/kit/examples/addsvc/server"
 	thriftadd "github.com/go-kit/kit/examples/addsvc/thrift/gen-go/add"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport/thrift"
 )
 
 type thriftBinding struct {
-	server.AddService
+	sum, concat thrift.Handler
+}
+
+func newThriftBinding(ctx context.Context, svc server.AddService, logger log.Logger) thriftBinding {
+	return thriftBinding{
+		sum: thrift.NewServer(
+			ctx,
+			makeSumEndpoint(svc),
+			decodeThriftSumRequest,
+			encodeThriftSumResponse,
+			thrift.ServerErrorLogger(logger),
+		),
+		concat: thrift.NewServer(
+			ctx,
+			makeConcatEndpoint(svc),
+			decodeThriftConcatRequest,
+			encodeThriftConcatResponse,
+			thrift.ServerErrorLogger(logger),
+		),
+	}
 }
 
 func (tb thriftBinding) Sum(a, b int64) (*thriftadd.SumReply, error) {
-	v := tb.AddService.Sum(int(a), int(b))
-	return &thriftadd.SumReply{Value: int64(v)}, nil
+	reply, err := tb.sum.ServeThrift(&thriftadd.AddServiceSumArgs{A: a, B: b})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*thriftadd.SumReply), nil
 }
 
 func (tb thriftBinding) Concat(a, b string) (*thriftadd.ConcatReply, error) {
-	v := tb.AddService.Concat(a, b)
-	return &thriftadd.ConcatReply{Value: v}, nil
+	reply, err := tb.concat.ServeThrift(&thriftadd.AddServiceConcatArgs{A: a, B: b})
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*thriftadd.ConcatReply), nil
+}
+
+func decodeThriftSumRequest(_ context.Context, request interface{}) (interface{}, error) {
+	args := request.(*thriftadd.AddServiceSumArgs)
+	return &server.SumRequest{A: int(args.A), B: int(args.B)}, nil
+}
+
+func encodeThriftSumResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(server.SumResponse)
+	return &thriftadd.SumReply{Value: int64(resp.V)}, nil
+}
+
+func decodeThriftConcatRequest(_ context.Context, request interface{}) (interface{}, error) {
+	args := request.(*thriftadd.AddServiceConcatArgs)
+	return &server.ConcatRequest{A: args.A, B: args.B}, nil
+}
+
+func encodeThriftConcatResponse(_ context.Context, response interface{}) (interface{}, error) {
+	resp := response.(server.ConcatResponse)
+	return &thriftadd.ConcatReply{Value: resp.V}, nil
 }
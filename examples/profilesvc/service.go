@@ -1,7 +1,7 @@
 package main
 
 import (
-	"errors"
+	"net/http"
 	"sync"
 
 	"golang.org/x/net/context"
@@ -36,11 +36,23 @@ type Address struct {
 }
 
 var (
-	errInconsistentIDs = errors.New("inconsistent IDs")
-	errAlreadyExists   = errors.New("already exists")
-	errNotFound        = errors.New("not found")
+	errInconsistentIDs error = serviceError{"inconsistent IDs", http.StatusBadRequest}
+	errAlreadyExists   error = serviceError{"already exists", http.StatusBadRequest}
+	errNotFound        error = serviceError{"not found", http.StatusNotFound}
 )
 
+// serviceError is an error of the service, with the status code it's
+// reported with by the HTTP transport.
+type serviceError struct {
+	msg  string
+	code int
+}
+
+func (e serviceError) Error() string { return e.msg }
+
+// StatusCode implements the StatusCoder interface of transport/http.
+func (e serviceError) StatusCode() int { return e.code }
+
 type inmemService struct {
 	mtx sync.RWMutex
 	m   map[string]Profile
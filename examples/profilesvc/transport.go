@@ -22,7 +22,7 @@ func makeHandler(ctx context.Context, s ProfileService, logger kitlog.Logger) st
 
 	commonOptions := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
-		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerErrorEncoder(kithttp.EncodeJSONError),
 	}
 
 	// POST    /profiles                           adds another profile
@@ -232,39 +232,8 @@ func encodeResponse(ctx context.Context, w stdhttp.ResponseWriter, response inte
 	if e, ok := response.(errorer); ok && e.error() != nil {
 		// Not a Go kit transport error, but a business-logic error.
 		// Provide those as HTTP errors.
-		encodeError(ctx, e.error(), w)
+		kithttp.EncodeJSONError(ctx, e.error(), w)
 		return nil
 	}
 	return json.NewEncoder(w).Encode(response)
 }
-
-func encodeError(_ context.Context, err error, w stdhttp.ResponseWriter) {
-	if err == nil {
-		panic("encodeError with nil error")
-	}
-	w.WriteHeader(codeFrom(err))
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
-}
-
-func codeFrom(err error) int {
-	switch err {
-	case errNotFound:
-		return stdhttp.StatusNotFound
-	case errAlreadyExists, errInconsistentIDs:
-		return stdhttp.StatusBadRequest
-	default:
-		if e, ok := err.(kithttp.Error); ok {
-			switch e.Domain {
-			case kithttp.DomainDecode:
-				return stdhttp.StatusBadRequest
-			case kithttp.DomainDo:
-				return stdhttp.StatusServiceUnavailable
-			default:
-				return stdhttp.StatusInternalServerError
-			}
-		}
-		return stdhttp.StatusInternalServerError
-	}
-}
@@ -3,7 +3,7 @@
 package booking
 
 import (
-	"errors"
+	"net/http"
 	"time"
 
 	"github.com/go-kit/kit/examples/shipping/cargo"
@@ -12,7 +12,15 @@ import (
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
-var ErrInvalidArgument = errors.New("invalid argument")
+var ErrInvalidArgument error = invalidArgumentError("invalid argument")
+
+type invalidArgumentError string
+
+func (e invalidArgumentError) Error() string { return string(e) }
+
+// StatusCode implements the StatusCoder interface of transport/http, so that
+// HTTP servers report the error as Bad Request.
+func (e invalidArgumentError) StatusCode() int { return http.StatusBadRequest }
 
 // Service is the interface that provides booking methods.
 type Service interface {
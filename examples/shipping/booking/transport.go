@@ -19,7 +19,7 @@ import (
 func MakeHandler(ctx context.Context, bs Service, logger kitlog.Logger) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
-		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerErrorEncoder(kithttp.EncodeJSONError),
 	}
 
 	bookCargoHandler := kithttp.NewServer(
@@ -173,7 +173,7 @@ func decodeListLocationsRequest(_ context.Context, r *http.Request) (interface{}
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	if e, ok := response.(errorer); ok && e.error() != nil {
-		encodeError(ctx, e.error(), w)
+		kithttp.EncodeJSONError(ctx, e.error(), w)
 		return nil
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -183,19 +183,3 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 type errorer interface {
 	error() error
 }
-
-// encode errors from business-logic
-func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
-		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
-		w.WriteHeader(http.StatusBadRequest)
-	default:
-		w.WriteHeader(http.StatusInternalServerError)
-	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
-}
@@ -2,7 +2,7 @@
 package cargo
 
 import (
-	"errors"
+	"net/http"
 	"strings"
 	"time"
 
@@ -62,7 +62,15 @@ type Repository interface {
 }
 
 // ErrUnknown is used when a cargo could not be found.
-var ErrUnknown = errors.New("unknown cargo")
+var ErrUnknown error = unknownError("unknown cargo")
+
+type unknownError string
+
+func (e unknownError) Error() string { return string(e) }
+
+// StatusCode implements the StatusCoder interface of transport/http, so that
+// HTTP servers report the error as Not Found.
+func (e unknownError) StatusCode() int { return http.StatusNotFound }
 
 // NextTrackingID generates a new tracking ID.
 // TODO: Move to infrastructure(?)
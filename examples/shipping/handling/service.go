@@ -3,7 +3,7 @@
 package handling
 
 import (
-	"errors"
+	"net/http"
 	"time"
 
 	"github.com/go-kit/kit/examples/shipping/cargo"
@@ -13,7 +13,15 @@ import (
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
-var ErrInvalidArgument = errors.New("invalid argument")
+var ErrInvalidArgument error = invalidArgumentError("invalid argument")
+
+type invalidArgumentError string
+
+func (e invalidArgumentError) Error() string { return string(e) }
+
+// StatusCode implements the StatusCoder interface of transport/http, so that
+// HTTP servers report the error as Bad Request.
+func (e invalidArgumentError) StatusCode() int { return http.StatusBadRequest }
 
 // EventHandler provides a means of subscribing to registered handling events.
 type EventHandler interface {
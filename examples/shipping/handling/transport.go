@@ -21,7 +21,7 @@ func MakeHandler(ctx context.Context, hs Service, logger kitlog.Logger) http.Han
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
-		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerErrorEncoder(kithttp.EncodeJSONError),
 	}
 
 	registerIncidentHandler := kithttp.NewServer(
@@ -72,7 +72,7 @@ func stringToEventType(s string) cargo.HandlingEventType {
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	if e, ok := response.(errorer); ok && e.error() != nil {
-		encodeError(ctx, e.error(), w)
+		kithttp.EncodeJSONError(ctx, e.error(), w)
 		return nil
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -82,19 +82,3 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 type errorer interface {
 	error() error
 }
-
-// encode errors from business-logic
-func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
-		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
-		w.WriteHeader(http.StatusBadRequest)
-	default:
-		w.WriteHeader(http.StatusInternalServerError)
-	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
-}
@@ -3,8 +3,8 @@
 package tracking
 
 import (
-	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,7 +12,15 @@ import (
 )
 
 // ErrInvalidArgument is returned when one or more arguments are invalid.
-var ErrInvalidArgument = errors.New("invalid argument")
+var ErrInvalidArgument error = invalidArgumentError("invalid argument")
+
+type invalidArgumentError string
+
+func (e invalidArgumentError) Error() string { return string(e) }
+
+// StatusCode implements the StatusCoder interface of transport/http, so that
+// HTTP servers report the error as Bad Request.
+func (e invalidArgumentError) StatusCode() int { return http.StatusBadRequest }
 
 // Service is the interface that provides the basic Track method.
 type Service interface {
@@ -8,7 +8,6 @@ import (
 	"github.com/gorilla/mux"
 	"golang.org/x/net/context"
 
-	"github.com/go-kit/kit/examples/shipping/cargo"
 	kitlog "github.com/go-kit/kit/log"
 	kithttp "github.com/go-kit/kit/transport/http"
 )
@@ -19,7 +18,7 @@ func MakeHandler(ctx context.Context, ts Service, logger kitlog.Logger) http.Han
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorLogger(logger),
-		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerErrorEncoder(kithttp.EncodeJSONError),
 	}
 
 	trackCargoHandler := kithttp.NewServer(
@@ -46,7 +45,7 @@ func decodeTrackCargoRequest(_ context.Context, r *http.Request) (interface{}, e
 
 func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
 	if e, ok := response.(errorer); ok && e.error() != nil {
-		encodeError(ctx, e.error(), w)
+		kithttp.EncodeJSONError(ctx, e.error(), w)
 		return nil
 	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -56,19 +55,3 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 type errorer interface {
 	error() error
 }
-
-// encode errors from business-logic
-func encodeError(_ context.Context, err error, w http.ResponseWriter) {
-	switch err {
-	case cargo.ErrUnknown:
-		w.WriteHeader(http.StatusNotFound)
-	case ErrInvalidArgument:
-		w.WriteHeader(http.StatusBadRequest)
-	default:
-		w.WriteHeader(http.StatusInternalServerError)
-	}
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": err.Error(),
-	})
-}
@@ -57,14 +57,14 @@ func main() {
 	uppercaseHandler := httptransport.NewServer(
 		ctx,
 		makeUppercaseEndpoint(svc),
-		decodeUppercaseRequest,
-		encodeResponse,
+		httptransport.DecodeJSONRequest(uppercaseRequest{}),
+		httptransport.EncodeJSONResponse,
 	)
 	countHandler := httptransport.NewServer(
 		ctx,
 		makeCountEndpoint(svc),
-		decodeCountRequest,
-		encodeResponse,
+		httptransport.DecodeJSONRequest(countRequest{}),
+		httptransport.EncodeJSONResponse,
 	)
 
 	http.Handle("/uppercase", uppercaseHandler)
@@ -88,7 +88,7 @@ func makeUppercaseProxy(ctx context.Context, instance string) endpoint.Endpoint
 	return httptransport.NewClient(
 		"GET",
 		u,
-		encodeRequest,
+		httptransport.EncodeJSONRequest,
 		decodeUppercaseResponse,
 	).Endpoint()
 }
@@ -0,0 +1,194 @@
+package loadbalancer
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// KeyFunc extracts the key that a request is routed by, e.g. a cache key or a
+// tenant ID.
+type KeyFunc func(ctx context.Context, request interface{}) string
+
+// ConsistentHash is a load balancer that routes requests with the same key to
+// the same instance, using rendezvous hashing over the instance strings of
+// the publisher. When instances come and go, only the keys of the affected
+// instances move.
+//
+// With BoundedLoad, no instance is given more than its share of the
+// in-flight requests times the load factor; requests for a hot key spill over
+// to the next instance in the key's order of preference. A request counts as
+// in flight while its endpoint is being called; endpoints that are picked but
+// never called don't count.
+type ConsistentHash struct {
+	p          InstancePublisher
+	key        KeyFunc
+	loadFactor float64
+
+	mtx    sync.Mutex
+	loads  map[string]*instanceLoad // in-flight requests per instance
+	total  int
+	pruned []InstanceEndpoint // the instances loads was last pruned to
+}
+
+type instanceLoad struct {
+	n int
+}
+
+// NewConsistentHash returns a new ConsistentHash load balancer, which routes
+// requests by the key extracted by key.
+func NewConsistentHash(p InstancePublisher, key KeyFunc, options ...ConsistentHashOption) *ConsistentHash {
+	ch := &ConsistentHash{
+		p:     p,
+		key:   key,
+		loads: map[string]*instanceLoad{},
+	}
+	for _, option := range options {
+		option(ch)
+	}
+	return ch
+}
+
+// ConsistentHashOption sets an optional parameter for the ConsistentHash.
+type ConsistentHashOption func(*ConsistentHash)
+
+// BoundedLoad limits the in-flight requests of each instance to c times the
+// average, rounded up. c must be greater than 1; 1.25 is a good start. By
+// default, loads are not bounded, and in-flight requests aren't tracked.
+func BoundedLoad(c float64) ConsistentHashOption {
+	return func(ch *ConsistentHash) { ch.loadFactor = c }
+}
+
+// EndpointFor implements ContextLoadBalancer.
+func (ch *ConsistentHash) EndpointFor(ctx context.Context, request interface{}) (endpoint.Endpoint, error) {
+	ies, err := ch.p.InstanceEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	if len(ies) <= 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	key := ch.key(ctx, request)
+	if ch.loadFactor <= 0 {
+		return ies[pickRendezvous(key, ies, nil)].Endpoint, nil
+	}
+
+	ch.mtx.Lock()
+	defer ch.mtx.Unlock()
+	ch.pruneLocked(ies)
+	bound := int(math.Ceil(ch.loadFactor * float64(ch.total+1) / float64(len(ies))))
+	ie := ies[pickRendezvous(key, ies, func(instance string) bool {
+		l, ok := ch.loads[instance]
+		return !ok || l.n < bound
+	})]
+	return ch.track(ie), nil
+}
+
+// pickRendezvous returns the index of the instance with the highest score for
+// key, among those accepted by ok, or among all if none is.
+func pickRendezvous(key string, ies []InstanceEndpoint, ok func(instance string) bool) int {
+	var (
+		best, fallback           = -1, -1
+		bestScore, fallbackScore uint64
+	)
+	for i, ie := range ies {
+		score := rendezvousScore(key, ie.Instance)
+		if fallback < 0 || score > fallbackScore {
+			fallback, fallbackScore = i, score
+		}
+		if ok != nil && !ok(ie.Instance) {
+			continue
+		}
+		if best < 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return fallback
+	}
+	return best
+}
+
+// track wraps the endpoint to count its in-flight requests.
+func (ch *ConsistentHash) track(ie InstanceEndpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ch.mtx.Lock()
+		l := ch.acquireLocked(ie.Instance)
+		ch.mtx.Unlock()
+		defer func() {
+			ch.mtx.Lock()
+			ch.releaseLocked(ie.Instance, l)
+			ch.mtx.Unlock()
+		}()
+		return ie.Endpoint(ctx, request)
+	}
+}
+
+func (ch *ConsistentHash) acquireLocked(instance string) *instanceLoad {
+	l, ok := ch.loads[instance]
+	if !ok {
+		l = &instanceLoad{}
+		ch.loads[instance] = l
+	}
+	l.n++
+	ch.total++
+	return l
+}
+
+// releaseLocked ends a request counted in l. Requests to instances that have
+// been pruned since they started are no longer part of the total.
+func (ch *ConsistentHash) releaseLocked(instance string, l *instanceLoad) {
+	l.n--
+	if ch.loads[instance] != l {
+		return
+	}
+	ch.total--
+	if l.n <= 0 {
+		delete(ch.loads, instance)
+	}
+}
+
+// pruneLocked drops the loads of instances that are no longer among ies, so
+// that requests still in flight to them don't count against the others. It
+// only looks when the publisher yields a different slice.
+func (ch *ConsistentHash) pruneLocked(ies []InstanceEndpoint) {
+	if sameInstanceEndpoints(ch.pruned, ies) {
+		return
+	}
+	ch.pruned = ies
+	if len(ch.loads) == 0 {
+		return
+	}
+	live := make(map[string]bool, len(ies))
+	for _, ie := range ies {
+		live[ie.Instance] = true
+	}
+	for instance, l := range ch.loads {
+		if !live[instance] {
+			ch.total -= l.n
+			delete(ch.loads, instance)
+		}
+	}
+}
+
+// rendezvousScore returns the weight of the instance for the key.
+func rendezvousScore(key, instance string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(instance))
+	// FNV mixes the last bytes poorly, so finalize as in SplitMix64.
+	return mix64(h.Sum64())
+}
+
+// mix64 is the SplitMix64 finalizer.
+func mix64(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,182 @@
+package loadbalancer_test
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
+	"github.com/go-kit/kit/log"
+)
+
+func TestConsistentHashStable(t *testing.T) {
+	var (
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			return func(context.Context, interface{}) (interface{}, error) { return instance, nil }, nil, nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		key   = func(_ context.Context, request interface{}) string { return request.(string) }
+		lb    = loadbalancer.NewConsistentHash(cache, key)
+		ctx   = context.Background()
+	)
+
+	route := func() map[string]string {
+		m := map[string]string{}
+		for i := 0; i < 1000; i++ {
+			k := fmt.Sprintf("key-%d", i)
+			e, err := lb.EndpointFor(ctx, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			instance, _ := e(ctx, k)
+			m[k] = instance.(string)
+		}
+		return m
+	}
+
+	if _, err := lb.EndpointFor(ctx, "key"); err != loadbalancer.ErrNoEndpoints {
+		t.Errorf("want %v, have %v", loadbalancer.ErrNoEndpoints, err)
+	}
+
+	cache.Replace([]string{"a:1", "b:1", "c:1", "d:1"})
+	before := route()
+	counts := map[string]int{}
+	for _, instance := range before {
+		counts[instance]++
+	}
+	for instance, n := range counts {
+		if n < 150 || n > 350 {
+			t.Errorf("%s: %d of 1000 keys, want about 250", instance, n)
+		}
+	}
+
+	cache.Replace([]string{"a:1", "b:1", "d:1"})
+	after := route()
+	for k, instance := range before {
+		if instance != "c:1" && after[k] != instance {
+			t.Errorf("%s: moved from %s to %s, but only c:1 was removed", k, instance, after[k])
+		}
+	}
+}
+
+func TestConsistentHashBoundedLoad(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		started sync.WaitGroup
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			return func(context.Context, interface{}) (interface{}, error) {
+				started.Done()
+				<-release
+				return instance, nil
+			}, nil, nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		key   = func(context.Context, interface{}) string { return "hot" }
+		lb    = loadbalancer.NewConsistentHash(cache, key, loadbalancer.BoundedLoad(1.25))
+		ctx   = context.Background()
+	)
+	cache.Replace([]string{"a:1", "b:1", "c:1", "d:1"})
+
+	// Send 8 concurrent requests for the same key. Each instance may have at
+	// most ceil(1.25 * n / 4) in flight, so the hot instance takes some, and
+	// the rest spill over.
+	var (
+		results = make(chan string, 8)
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		e, err := lb.EndpointFor(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		started.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance, _ := e(ctx, nil)
+			results <- instance.(string)
+		}()
+		started.Wait() // make the request count as in flight before the next
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	counts := map[string]int{}
+	for instance := range results {
+		counts[instance]++
+	}
+	if len(counts) < 2 {
+		t.Errorf("want requests to spill over, have %v", counts)
+	}
+	for instance, n := range counts {
+		if n > 3 { // ceil(1.25 * 8 / 4)
+			t.Errorf("%s: %d requests in flight, want at most 3", instance, n)
+		}
+	}
+}
+
+func TestConsistentHashBoundedLoadPickWithoutCall(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		started = make(chan string)
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			return func(_ context.Context, request interface{}) (interface{}, error) {
+				if request == "hold" {
+					started <- instance
+					<-release
+				}
+				return instance, nil
+			}, nil, nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		key   = func(context.Context, interface{}) string { return "hot" }
+		lb    = loadbalancer.NewConsistentHash(cache, key, loadbalancer.BoundedLoad(1.25))
+		ctx   = context.Background()
+	)
+	cache.Replace([]string{"a:1", "b:1", "c:1", "d:1"})
+	e, err := lb.EndpointFor(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, _ := e(ctx, nil)
+
+	// Endpoints that are picked but never called, concurrently, as by
+	// callers that return early, don't count as load.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := lb.EndpointFor(ctx, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// With nothing in flight, the first request takes the only slot of the
+	// home instance, ceil(1.25 * 1 / 4), and the second spills over.
+	var held []string
+	for i := 0; i < 2; i++ {
+		e, err := lb.EndpointFor(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); e(ctx, "hold") }()
+		held = append(held, <-started)
+	}
+	close(release)
+	wg.Wait()
+	if want, have := home, held[0]; want != have {
+		t.Errorf("first request: want %v, have %s", want, have)
+	}
+	if held[1] == home {
+		t.Errorf("second request: want another instance than %v", home)
+	}
+}
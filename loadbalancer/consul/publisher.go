@@ -2,6 +2,7 @@ package consul
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	consul "github.com/hashicorp/consul/api"
@@ -13,6 +14,15 @@ import (
 
 const defaultIndex = 0
 
+// Consul services have no metadata other than tags, so the publisher reads
+// the weight and priority of each instance from tags with these prefixes,
+// e.g. "weight=10" and "priority=1". Instances without them get
+// loadbalancer.DefaultMetadata; malformed values are ignored.
+const (
+	WeightTagPrefix   = "weight="
+	PriorityTagPrefix = "priority="
+)
+
 // Publisher yields endpoints for a service in Consul. Updates to the service
 // are watched and will update the Publisher endpoints.
 type Publisher struct {
@@ -50,7 +60,7 @@ func NewPublisher(
 	} else {
 		logger.Log("service", service, "tags", strings.Join(tags, ", "), "err", err)
 	}
-	p.cache.Replace(instances)
+	p.cache.ReplaceWithMetadata(instances)
 
 	go p.loop(index)
 
@@ -62,6 +72,11 @@ func (p *Publisher) Endpoints() ([]endpoint.Endpoint, error) {
 	return p.cache.Endpoints()
 }
 
+// InstanceEndpoints implements the InstancePublisher interface.
+func (p *Publisher) InstanceEndpoints() ([]loadbalancer.InstanceEndpoint, error) {
+	return p.cache.InstanceEndpoints()
+}
+
 // Stop terminates the publisher.
 func (p *Publisher) Stop() {
 	close(p.quitc)
@@ -90,7 +105,7 @@ func (p *Publisher) loop(lastIndex uint64) {
 		case err := <-errc:
 			p.logger.Log("service", p.service, "err", err)
 		case res := <-resc:
-			p.cache.Replace(res.instances)
+			p.cache.ReplaceWithMetadata(res.instances)
 			lastIndex = res.index
 		case <-p.quitc:
 			return
@@ -98,7 +113,7 @@ func (p *Publisher) loop(lastIndex uint64) {
 	}
 }
 
-func (p *Publisher) getInstances(lastIndex uint64) ([]string, uint64, error) {
+func (p *Publisher) getInstances(lastIndex uint64) (map[string]loadbalancer.Metadata, uint64, error) {
 	tag := ""
 
 	if len(p.tags) > 0 {
@@ -131,7 +146,7 @@ func (p *Publisher) getInstances(lastIndex uint64) ([]string, uint64, error) {
 // index.
 type response struct {
 	index     uint64
-	instances []string
+	instances map[string]loadbalancer.Metadata
 }
 
 func filterEntries(entries []*consul.ServiceEntry, tags ...string) []*consul.ServiceEntry {
@@ -157,18 +172,37 @@ ENTRIES:
 	return es
 }
 
-func makeInstances(entries []*consul.ServiceEntry) []string {
-	instances := make([]string, len(entries))
+func makeInstances(entries []*consul.ServiceEntry) map[string]loadbalancer.Metadata {
+	instances := make(map[string]loadbalancer.Metadata, len(entries))
 
-	for i, entry := range entries {
+	for _, entry := range entries {
 		addr := entry.Node.Address
 
 		if entry.Service.Address != "" {
 			addr = entry.Service.Address
 		}
 
-		instances[i] = fmt.Sprintf("%s:%d", addr, entry.Service.Port)
+		instances[fmt.Sprintf("%s:%d", addr, entry.Service.Port)] = makeMetadata(entry.Service.Tags)
 	}
 
 	return instances
 }
+
+func makeMetadata(tags []string) loadbalancer.Metadata {
+	metadata := loadbalancer.DefaultMetadata
+
+	for _, tag := range tags {
+		switch {
+		case strings.HasPrefix(tag, WeightTagPrefix):
+			if n, err := strconv.Atoi(strings.TrimPrefix(tag, WeightTagPrefix)); err == nil && n >= 0 {
+				metadata.Weight = n
+			}
+		case strings.HasPrefix(tag, PriorityTagPrefix):
+			if n, err := strconv.Atoi(strings.TrimPrefix(tag, PriorityTagPrefix)); err == nil {
+				metadata.Priority = n
+			}
+		}
+	}
+
+	return metadata
+}
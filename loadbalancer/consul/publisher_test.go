@@ -8,6 +8,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
 	"github.com/go-kit/kit/log"
 )
 
@@ -157,6 +158,23 @@ func TestPublisherAddressOverride(t *testing.T) {
 	}
 }
 
+func TestMakeMetadata(t *testing.T) {
+	for _, tc := range []struct {
+		tags []string
+		want loadbalancer.Metadata
+	}{
+		{nil, loadbalancer.DefaultMetadata},
+		{[]string{"api", "weight=10", "priority=2"}, loadbalancer.Metadata{Weight: 10, Priority: 2}},
+		{[]string{"weight=0"}, loadbalancer.Metadata{Weight: 0}},
+		{[]string{"weight=heavy", "priority="}, loadbalancer.DefaultMetadata},
+		{[]string{"weight=-1"}, loadbalancer.DefaultMetadata},
+	} {
+		if have := makeMetadata(tc.tags); tc.want != have {
+			t.Errorf("%v: want %v, have %v", tc.tags, tc.want, have)
+		}
+	}
+}
+
 type testClient struct {
 	entries []*consul.ServiceEntry
 }
@@ -11,7 +11,8 @@ import (
 )
 
 // Publisher yields endpoints taken from the named DNS SRV record. The name is
-// resolved on a fixed schedule. Priorities and weights are ignored.
+// resolved on a fixed schedule. The priority and weight of each target are
+// kept as the instance's metadata, for use by e.g. WeightedRoundRobin.
 type Publisher struct {
 	name   string
 	cache  *loadbalancer.EndpointCache
@@ -56,7 +57,7 @@ func NewPublisherDetailed(
 	} else {
 		logger.Log("name", name, "err", err)
 	}
-	p.cache.Replace(instances)
+	p.cache.ReplaceWithMetadata(instances)
 
 	go p.loop(refreshTicker, lookupSRV)
 	return p
@@ -80,7 +81,7 @@ func (p *Publisher) loop(
 				p.logger.Log(p.name, err)
 				continue // don't replace potentially-good with bad
 			}
-			p.cache.Replace(instances)
+			p.cache.ReplaceWithMetadata(instances)
 
 		case <-p.quit:
 			return
@@ -93,14 +94,22 @@ func (p *Publisher) Endpoints() ([]endpoint.Endpoint, error) {
 	return p.cache.Endpoints()
 }
 
-func (p *Publisher) resolve(lookupSRV func(service, proto, name string) (cname string, addrs []*net.SRV, err error)) ([]string, error) {
+// InstanceEndpoints implements the InstancePublisher interface.
+func (p *Publisher) InstanceEndpoints() ([]loadbalancer.InstanceEndpoint, error) {
+	return p.cache.InstanceEndpoints()
+}
+
+func (p *Publisher) resolve(lookupSRV func(service, proto, name string) (cname string, addrs []*net.SRV, err error)) (map[string]loadbalancer.Metadata, error) {
 	_, addrs, err := lookupSRV("", "", p.name)
 	if err != nil {
-		return []string{}, err
+		return map[string]loadbalancer.Metadata{}, err
 	}
-	instances := make([]string, len(addrs))
-	for i, addr := range addrs {
-		instances[i] = net.JoinHostPort(addr.Target, fmt.Sprint(addr.Port))
+	instances := make(map[string]loadbalancer.Metadata, len(addrs))
+	for _, addr := range addrs {
+		instances[net.JoinHostPort(addr.Target, fmt.Sprint(addr.Port))] = loadbalancer.Metadata{
+			Weight:   int(addr.Weight),
+			Priority: int(addr.Priority),
+		}
 	}
 	return instances, nil
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
 	"github.com/go-kit/kit/log"
 )
 
@@ -90,6 +92,39 @@ func TestBadFactory(t *testing.T) {
 	}
 }
 
+func TestMetadata(t *testing.T) {
+	var (
+		addrs = []*net.SRV{
+			{Target: "a", Port: 1, Priority: 10, Weight: 60},
+			{Target: "b", Port: 2, Priority: 20, Weight: 5},
+		}
+		ticker    = time.NewTicker(time.Second)
+		lookupSRV = func(string, string, string) (string, []*net.SRV, error) { return "", addrs, nil }
+		e         = func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+		factory   = func(string) (endpoint.Endpoint, io.Closer, error) { return e, nil, nil }
+		logger    = log.NewNopLogger()
+	)
+
+	p := NewPublisherDetailed("some-name", ticker, lookupSRV, factory, logger)
+	defer p.Stop()
+
+	ies, err := p.InstanceEndpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	have := map[string]loadbalancer.Metadata{}
+	for _, ie := range ies {
+		have[ie.Instance] = ie.Metadata
+	}
+	want := map[string]loadbalancer.Metadata{
+		"a:1": {Weight: 60, Priority: 10},
+		"b:2": {Weight: 5, Priority: 20},
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
 func TestRefreshWithChange(t *testing.T) {
 	t.Skip("TODO")
 }
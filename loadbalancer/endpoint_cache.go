@@ -25,6 +25,7 @@ type EndpointCache struct {
 	f      Factory
 	m      map[string]endpointCloser
 	cache  atomic.Value //[]endpoint.Endpoint
+	ies    atomic.Value //[]InstanceEndpoint
 	logger log.Logger
 }
 
@@ -39,6 +40,7 @@ func NewEndpointCache(f Factory, logger log.Logger) *EndpointCache {
 	}
 
 	endpointCache.cache.Store(make([]endpoint.Endpoint, 0))
+	endpointCache.ies.Store(make([]InstanceEndpoint, 0))
 
 	return endpointCache
 }
@@ -46,21 +48,34 @@ func NewEndpointCache(f Factory, logger log.Logger) *EndpointCache {
 type endpointCloser struct {
 	endpoint.Endpoint
 	io.Closer
+	metadata Metadata
 }
 
 // Replace replaces the current set of endpoints with endpoints manufactured
 // by the passed instances. If the same instance exists in both the existing
-// and new sets, it's left untouched.
+// and new sets, it's left untouched. All instances get DefaultMetadata.
 func (t *EndpointCache) Replace(instances []string) {
+	metadata := make(map[string]Metadata, len(instances))
+	for _, instance := range instances {
+		metadata[instance] = DefaultMetadata
+	}
+	t.ReplaceWithMetadata(metadata)
+}
+
+// ReplaceWithMetadata is like Replace, but takes the metadata of each
+// instance as well. The metadata of instances that exist in both sets is
+// updated.
+func (t *EndpointCache) ReplaceWithMetadata(instances map[string]Metadata) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
 
 	// Produce the current set of endpoints.
 	oldMap := t.m
 	t.m = make(map[string]endpointCloser, len(instances))
-	for _, instance := range instances {
+	for instance, metadata := range instances {
 		// If it already exists, just copy it over.
 		if ec, ok := oldMap[instance]; ok {
+			ec.metadata = metadata
 			t.m[instance] = ec
 			delete(oldMap, instance)
 			continue
@@ -72,7 +87,7 @@ func (t *EndpointCache) Replace(instances []string) {
 			t.logger.Log("instance", instance, "err", err)
 			continue
 		}
-		t.m[instance] = endpointCloser{endpoint, closer}
+		t.m[instance] = endpointCloser{endpoint, closer, metadata}
 	}
 
 	t.refreshCache()
@@ -90,6 +105,7 @@ func (t *EndpointCache) refreshCache() {
 		length    = len(t.m)
 		instances = make([]string, 0, length)
 		newCache  = make([]endpoint.Endpoint, 0, length)
+		newIEs    = make([]InstanceEndpoint, 0, length)
 	)
 
 	for instance, _ := range t.m {
@@ -100,9 +116,11 @@ func (t *EndpointCache) refreshCache() {
 
 	for _, instance := range instances {
 		newCache = append(newCache, t.m[instance].Endpoint)
+		newIEs = append(newIEs, InstanceEndpoint{Instance: instance, Endpoint: t.m[instance].Endpoint, Metadata: t.m[instance].metadata})
 	}
 
 	t.cache.Store(newCache)
+	t.ies.Store(newIEs)
 }
 
 // Endpoints returns the current set of endpoints in undefined order. Satisfies
@@ -110,3 +128,9 @@ func (t *EndpointCache) refreshCache() {
 func (t *EndpointCache) Endpoints() ([]endpoint.Endpoint, error) {
 	return t.cache.Load().([]endpoint.Endpoint), nil
 }
+
+// InstanceEndpoints returns the current set of endpoints together with their
+// instance strings and metadata, sorted by instance. Satisfies InstancePublisher interface.
+func (t *EndpointCache) InstanceEndpoints() ([]InstanceEndpoint, error) {
+	return t.ies.Load().([]InstanceEndpoint), nil
+}
@@ -89,4 +89,46 @@ func BenchmarkEndpoints(b *testing.B) {
 			ec.Endpoints()
 		}
 	})
-}
\ No newline at end of file
+}
+
+func BenchmarkLeastOutstanding(b *testing.B) {
+	benchmarkLoadBalancer(b, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewLeastOutstanding(p)
+	})
+}
+
+func BenchmarkP2C(b *testing.B) {
+	benchmarkLoadBalancer(b, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewP2C(p, 123)
+	})
+}
+
+func BenchmarkPeakEWMA(b *testing.B) {
+	benchmarkLoadBalancer(b, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewPeakEWMA(p, 123)
+	})
+}
+
+func benchmarkLoadBalancer(b *testing.B, newLoadBalancer func(loadbalancer.Publisher) loadbalancer.LoadBalancer) {
+	var (
+		e   = func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+		f   = func(string) (endpoint.Endpoint, io.Closer, error) { return e, make(closer), nil }
+		ec  = loadbalancer.NewEndpointCache(f, log.NewNopLogger())
+		lb  = newLoadBalancer(ec)
+		ctx = context.Background()
+	)
+
+	b.ReportAllocs()
+
+	ec.Replace([]string{"a", "b", "c", "d"})
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e, err := lb.Endpoint()
+			if err != nil {
+				b.Fatal(err)
+			}
+			e(ctx, struct{}{})
+		}
+	})
+}
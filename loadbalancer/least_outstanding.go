@@ -0,0 +1,44 @@
+package loadbalancer
+
+import (
+	"sync/atomic"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// LeastOutstanding is a load balancer that returns the published endpoint
+// with the fewest in-flight requests, so that slow endpoints receive less
+// traffic. Ties are broken in round-robin order.
+type LeastOutstanding struct {
+	t       *tracker
+	counter uint64
+}
+
+// NewLeastOutstanding returns a new LeastOutstanding load balancer.
+func NewLeastOutstanding(p Publisher) *LeastOutstanding {
+	return &LeastOutstanding{t: newTracker(p, newTrackedEndpoint)}
+}
+
+// Endpoint implements the LoadBalancer interface.
+func (lo *LeastOutstanding) Endpoint() (endpoint.Endpoint, error) {
+	nodes, err := lo.t.nodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) <= 0 {
+		return nil, ErrNoEndpoints
+	}
+	var (
+		n     = uint64(len(nodes))
+		start = atomic.AddUint64(&lo.counter, 1)
+		best  = nodes[start%n]
+		min   = best.outstanding()
+	)
+	for i := uint64(1); i < n && min > 0; i++ {
+		node := nodes[(start+i)%n]
+		if o := node.outstanding(); o < min {
+			best, min = node, o
+		}
+	}
+	return best.endpoint, nil
+}
@@ -0,0 +1,247 @@
+package loadbalancer_test
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
+	"github.com/go-kit/kit/loadbalancer/fixed"
+	"github.com/go-kit/kit/log"
+)
+
+func TestLeastOutstandingAvoidsBusyEndpoints(t *testing.T) {
+	var (
+		n         = 3
+		endpoints = make([]endpoint.Endpoint, n)
+		counts    = make([]int, n)
+		block     = true
+		busy      = make(chan int)
+		release   = make(chan struct{})
+		ctx       = context.Background()
+	)
+	for i := 0; i < n; i++ {
+		i0 := i
+		endpoints[i] = func(context.Context, interface{}) (interface{}, error) {
+			if block {
+				busy <- i0
+				<-release
+				return struct{}{}, nil
+			}
+			counts[i0]++
+			return struct{}{}, nil
+		}
+	}
+	lb := loadbalancer.NewLeastOutstanding(fixed.NewPublisher(endpoints))
+
+	e, err := lb.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() { defer close(done); e(ctx, struct{}{}) }()
+	b := <-busy
+	block = false
+
+	for i := 0; i < 100; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e(ctx, struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want, have := 0, counts[b]; want != have {
+		t.Errorf("busy endpoint %d: want %d, have %d", b, want, have)
+	}
+
+	close(release)
+	<-done
+
+	for i := range counts {
+		counts[i] = 0
+	}
+	for i := 0; i < 99; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e(ctx, struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, have := range counts {
+		if want := 33; want != have {
+			t.Errorf("%d: want %d, have %d", i, want, have)
+		}
+	}
+}
+
+func TestLeastOutstandingKeepsCountsAcrossReplace(t *testing.T) {
+	testKeepsCountsAcrossReplace(t, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewLeastOutstanding(p)
+	})
+}
+
+func TestLeastOutstandingInstanceFlap(t *testing.T) {
+	testInstanceFlap(t, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewLeastOutstanding(p)
+	})
+}
+
+func TestLeastOutstandingPublisherChange(t *testing.T) {
+	var (
+		ctx = context.Background()
+		p   = &mutablePublisher{}
+		lb  = loadbalancer.NewLeastOutstanding(p)
+	)
+	if _, have := lb.Endpoint(); have != loadbalancer.ErrNoEndpoints {
+		t.Fatalf("want %q, have %q", loadbalancer.ErrNoEndpoints, have)
+	}
+
+	var called bool
+	p.endpoints = []endpoint.Endpoint{func(context.Context, interface{}) (interface{}, error) { called = true; return struct{}{}, nil }}
+	e, err := lb.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e(ctx, struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("new endpoint wasn't called")
+	}
+}
+
+func TestLeastOutstandingNoEndpoints(t *testing.T) {
+	lb := loadbalancer.NewLeastOutstanding(fixed.NewPublisher([]endpoint.Endpoint{}))
+	_, have := lb.Endpoint()
+	if want := loadbalancer.ErrNoEndpoints; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+type mutablePublisher struct{ endpoints []endpoint.Endpoint }
+
+func (p *mutablePublisher) Endpoints() ([]endpoint.Endpoint, error) { return p.endpoints, nil }
+
+// testKeepsCountsAcrossReplace holds three requests, which the load balancer
+// spreads over two instances, and checks that new requests still avoid the
+// busier instance after the publisher is refreshed with the same instances.
+func testKeepsCountsAcrossReplace(t *testing.T, newLoadBalancer func(loadbalancer.Publisher) loadbalancer.LoadBalancer) {
+	var (
+		release = make(chan struct{})
+		held    = make(chan string)
+		counts  = map[string]int{}
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			return func(_ context.Context, request interface{}) (interface{}, error) {
+				if request == "hold" {
+					held <- instance
+					<-release
+				} else {
+					counts[instance]++
+				}
+				return instance, nil
+			}, nil, nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		lb    = newLoadBalancer(cache)
+		ctx   = context.Background()
+		wg    sync.WaitGroup
+	)
+	cache.Replace([]string{"a", "b"})
+
+	holds := map[string]int{}
+	for i := 0; i < 3; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() { defer wg.Done(); e(ctx, "hold") }()
+		holds[<-held]++
+	}
+	defer wg.Wait()
+	defer close(release)
+	busier := "a"
+	if holds["b"] > holds["a"] {
+		busier = "b"
+	}
+	if want, have := 2, holds[busier]; want != have {
+		t.Fatalf("want %d requests held by %s, have %d", want, busier, have)
+	}
+
+	cache.Replace([]string{"a", "b"})
+
+	for i := 0; i < 10; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e(ctx, struct{}{})
+	}
+	if want, have := 0, counts[busier]; want != have {
+		t.Errorf("busier instance %s: want %d requests, have %d", busier, want, have)
+	}
+}
+
+// testInstanceFlap removes an instance and adds it again, which makes the
+// EndpointCache close its endpoint and create a new one, and checks that the
+// load balancer calls the new endpoint.
+func testInstanceFlap(t *testing.T, newLoadBalancer func(loadbalancer.Publisher) loadbalancer.LoadBalancer) {
+	var (
+		mtx     sync.Mutex
+		closed  = map[int]bool{}
+		created int
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			mtx.Lock()
+			defer mtx.Unlock()
+			created++
+			id := created
+			e := func(context.Context, interface{}) (interface{}, error) {
+				mtx.Lock()
+				defer mtx.Unlock()
+				if closed[id] {
+					return nil, errors.New("closed endpoint")
+				}
+				return id, nil
+			}
+			return e, closerFunc(func() error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				closed[id] = true
+				return nil
+			}), nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		lb    = newLoadBalancer(cache)
+		ctx   = context.Background()
+	)
+	cache.Replace([]string{"a"})
+	for want := 1; want <= 2; want++ {
+		for i := 0; i < 3; i++ {
+			e, err := lb.Endpoint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			have, err := e(ctx, struct{}{})
+			if err != nil {
+				t.Fatalf("flap %d, call %d: %v", want-1, i, err)
+			}
+			if want != have {
+				t.Errorf("flap %d, call %d: want endpoint %d, have %v", want-1, i, want, have)
+			}
+		}
+		cache.Replace([]string{})
+		cache.Replace([]string{"a"})
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
@@ -3,6 +3,8 @@ package loadbalancer
 import (
 	"errors"
 
+	"golang.org/x/net/context"
+
 	"github.com/go-kit/kit/endpoint"
 )
 
@@ -12,6 +14,55 @@ type LoadBalancer interface {
 	Endpoint() (endpoint.Endpoint, error)
 }
 
+// ContextLoadBalancer describes something that can yield endpoints for a
+// remote service method, choosing based on the context and the request that
+// will be passed to the endpoint. It enables routing policies such as sticky
+// sessions or sharding by tenant.
+type ContextLoadBalancer interface {
+	EndpointFor(ctx context.Context, request interface{}) (endpoint.Endpoint, error)
+}
+
+// ContextLoadBalancerFunc is an adapter to allow the use of ordinary functions
+// as ContextLoadBalancers.
+type ContextLoadBalancerFunc func(ctx context.Context, request interface{}) (endpoint.Endpoint, error)
+
+// EndpointFor implements ContextLoadBalancer by calling f(ctx, request).
+func (f ContextLoadBalancerFunc) EndpointFor(ctx context.Context, request interface{}) (endpoint.Endpoint, error) {
+	return f(ctx, request)
+}
+
+// ContextAdapter adapts a LoadBalancer to the ContextLoadBalancer interface.
+// The context and the request are ignored.
+type ContextAdapter struct {
+	LoadBalancer
+}
+
+// EndpointFor implements ContextLoadBalancer.
+func (a ContextAdapter) EndpointFor(context.Context, interface{}) (endpoint.Endpoint, error) {
+	return a.Endpoint()
+}
+
+// BackgroundAdapter adapts a ContextLoadBalancer to the LoadBalancer
+// interface, for callers that have no request at hand. Endpoints are chosen
+// for a background context and a nil request.
+type BackgroundAdapter struct {
+	ContextLoadBalancer
+}
+
+// Endpoint implements LoadBalancer.
+func (a BackgroundAdapter) Endpoint() (endpoint.Endpoint, error) {
+	return a.EndpointFor(context.Background(), nil)
+}
+
+// contextLoadBalancer returns lb as a ContextLoadBalancer, adapting it only
+// if it doesn't implement the interface itself.
+func contextLoadBalancer(lb LoadBalancer) ContextLoadBalancer {
+	if clb, ok := lb.(ContextLoadBalancer); ok {
+		return clb
+	}
+	return ContextAdapter{lb}
+}
+
 // ErrNoEndpoints is returned when a load balancer (or one of its components)
 // has no endpoints to return. In a request lifecycle, this is usually a fatal
 // error.
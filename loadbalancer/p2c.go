@@ -0,0 +1,58 @@
+package loadbalancer
+
+import (
+	"sync/atomic"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// P2C is a load balancer that picks two published endpoints at random, and
+// returns the one with fewer in-flight requests. It adapts to slow endpoints
+// almost as well as LeastOutstanding, in constant time, and avoids sending
+// bursts to whichever endpoint was least loaded a moment ago.
+type P2C struct {
+	t     *tracker
+	state uint64
+}
+
+// NewP2C returns a new P2C load balancer.
+func NewP2C(p Publisher, seed int64) *P2C {
+	return &P2C{
+		t:     newTracker(p, newTrackedEndpoint),
+		state: uint64(seed),
+	}
+}
+
+// Endpoint implements the LoadBalancer interface.
+func (p *P2C) Endpoint() (endpoint.Endpoint, error) {
+	nodes, err := p.t.nodes()
+	if err != nil {
+		return nil, err
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, ErrNoEndpoints
+	case 1:
+		return nodes[0].endpoint, nil
+	}
+	i, j := pickTwo(random(&p.state), len(nodes))
+	a, b := nodes[i], nodes[j]
+	if b.outstanding() < a.outstanding() {
+		return b.endpoint, nil
+	}
+	return a.endpoint, nil
+}
+
+// random returns a pseudo-random number, without locking: each call advances
+// the state atomically, as in SplitMix64.
+func random(state *uint64) uint64 {
+	return mix64(atomic.AddUint64(state, 0x9e3779b97f4a7c15))
+}
+
+// pickTwo returns two distinct indexes in [0, n) derived from r. n must be at
+// least 2.
+func pickTwo(r uint64, n int) (int, int) {
+	i := r % uint64(n)
+	j := (i + 1 + (r>>32)%uint64(n-1)) % uint64(n)
+	return int(i), int(j)
+}
@@ -0,0 +1,119 @@
+package loadbalancer_test
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
+	"github.com/go-kit/kit/loadbalancer/fixed"
+)
+
+func TestP2CDistribution(t *testing.T) {
+	var (
+		n          = 3
+		endpoints  = make([]endpoint.Endpoint, n)
+		counts     = make([]int, n)
+		seed       = int64(123)
+		ctx        = context.Background()
+		iterations = 100000
+		want       = iterations / n
+		tolerance  = want / 100 // 1%
+	)
+
+	for i := 0; i < n; i++ {
+		i0 := i
+		endpoints[i] = func(context.Context, interface{}) (interface{}, error) { counts[i0]++; return struct{}{}, nil }
+	}
+
+	lb := loadbalancer.NewP2C(fixed.NewPublisher(endpoints), seed)
+
+	for i := 0; i < iterations; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e(ctx, struct{}{}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	for i, have := range counts {
+		if math.Abs(float64(want-have)) > float64(tolerance) {
+			t.Errorf("%d: want %d, have %d", i, want, have)
+		}
+	}
+}
+
+func TestP2CAvoidsBusyEndpoints(t *testing.T) {
+	var (
+		n         = 2
+		endpoints = make([]endpoint.Endpoint, n)
+		counts    = make([]int, n)
+		block     = true
+		busy      = make(chan int)
+		release   = make(chan struct{})
+		ctx       = context.Background()
+	)
+	for i := 0; i < n; i++ {
+		i0 := i
+		endpoints[i] = func(context.Context, interface{}) (interface{}, error) {
+			if block {
+				busy <- i0
+				<-release
+				return struct{}{}, nil
+			}
+			counts[i0]++
+			return struct{}{}, nil
+		}
+	}
+	lb := loadbalancer.NewP2C(fixed.NewPublisher(endpoints), 123)
+
+	e, err := lb.Endpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() { defer close(done); e(ctx, struct{}{}) }()
+	b := <-busy
+	block = false
+
+	// With two endpoints, P2C always compares both.
+	for i := 0; i < 100; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e(ctx, struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want, have := 0, counts[b]; want != have {
+		t.Errorf("busy endpoint %d: want %d, have %d", b, want, have)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestP2CKeepsCountsAcrossReplace(t *testing.T) {
+	testKeepsCountsAcrossReplace(t, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewP2C(p, 123)
+	})
+}
+
+func TestP2CInstanceFlap(t *testing.T) {
+	testInstanceFlap(t, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewP2C(p, 123)
+	})
+}
+
+func TestP2CNoEndpoints(t *testing.T) {
+	lb := loadbalancer.NewP2C(fixed.NewPublisher([]endpoint.Endpoint{}), 123)
+	_, have := lb.Endpoint()
+	if want := loadbalancer.ErrNoEndpoints; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,168 @@
+package loadbalancer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// PeakEWMA is a load balancer that scores each published endpoint by an
+// exponentially weighted moving average of its latency and error rate,
+// multiplied by its in-flight requests, and picks the better of two random
+// endpoints, as in Finagle and Linkerd.
+//
+// The latency average is peak-sensitive: a response slower than the average
+// replaces it outright, and it only decays back over time. Averages decay
+// towards zero while an endpoint receives no traffic, so that a penalized
+// endpoint is eventually retried. Scores are kept by instance, so with
+// publishers built on EndpointCache, they survive refreshes of the publisher.
+type PeakEWMA struct {
+	t       *tracker
+	decay   float64 // nanoseconds
+	penalty float64 // nanoseconds
+	now     func() time.Time
+	state   uint64
+}
+
+// NewPeakEWMA returns a new PeakEWMA load balancer.
+func NewPeakEWMA(p Publisher, seed int64, options ...PeakEWMAOption) *PeakEWMA {
+	pe := &PeakEWMA{
+		decay:   float64(10 * time.Second),
+		penalty: float64(time.Second),
+		now:     time.Now,
+		state:   uint64(seed),
+	}
+	for _, option := range options {
+		option(pe)
+	}
+	pe.t = newTracker(p, pe.newEndpoint)
+	return pe
+}
+
+// PeakEWMAOption sets an optional parameter for the PeakEWMA.
+type PeakEWMAOption func(*PeakEWMA)
+
+// DecayTime sets the time constant of the moving averages: an observation
+// loses about two thirds of its weight after d. By default, it's 10 seconds.
+func DecayTime(d time.Duration) PeakEWMAOption {
+	return func(pe *PeakEWMA) { pe.decay = float64(d) }
+}
+
+// FailurePenalty sets the latency added to the score of an endpoint for an
+// error rate of 1; each failed response moves the error rate a fifth of the
+// way towards 1. It's also the score of a new endpoint that has requests in
+// flight but no responses yet. By default, it's 1 second.
+func FailurePenalty(d time.Duration) PeakEWMAOption {
+	return func(pe *PeakEWMA) { pe.penalty = float64(d) }
+}
+
+// EWMAClock sets the function used to tell the current time. By default,
+// it's time.Now.
+func EWMAClock(now func() time.Time) PeakEWMAOption {
+	return func(pe *PeakEWMA) { pe.now = now }
+}
+
+// Endpoint implements the LoadBalancer interface.
+func (pe *PeakEWMA) Endpoint() (endpoint.Endpoint, error) {
+	nodes, err := pe.t.nodes()
+	if err != nil {
+		return nil, err
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, ErrNoEndpoints
+	case 1:
+		return nodes[0].endpoint, nil
+	}
+	var (
+		i, j = pickTwo(random(&pe.state), len(nodes))
+		a, b = nodes[i], nodes[j]
+		now  = pe.now()
+	)
+	if pe.score(b, now) < pe.score(a, now) {
+		return b.endpoint, nil
+	}
+	return a.endpoint, nil
+}
+
+// ewma holds the moving averages of an endpoint.
+type ewma struct {
+	mtx     sync.Mutex
+	stamp   time.Time
+	latency float64 // nanoseconds
+	errors  float64 // rate in [0, 1]
+}
+
+func (pe *PeakEWMA) newEndpoint(next endpoint.Endpoint) *trackedEndpoint {
+	t := &trackedEndpoint{ewma: &ewma{stamp: pe.now()}}
+	t.next.Store(next)
+	t.endpoint = func(ctx context.Context, request interface{}) (interface{}, error) {
+		atomic.AddInt64(&t.inflight, 1)
+		begin := pe.now()
+		response, err := t.forward(ctx, request)
+		end := pe.now()
+		atomic.AddInt64(&t.inflight, -1)
+		pe.observe(t.ewma, end, float64(end.Sub(begin)), err != nil)
+		return response, err
+	}
+	return t
+}
+
+// observe folds a response into the averages, after decaying them for the
+// time since the last update.
+func (pe *PeakEWMA) observe(e *ewma, now time.Time, latency float64, failed bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	w := pe.decayLocked(e, now)
+	if latency > e.latency {
+		e.latency = latency
+	} else {
+		e.latency = e.latency*w + latency*(1-w)
+	}
+	e.errors *= w
+	if failed {
+		e.errors += (1 - e.errors) * errorWeight
+	} else {
+		e.errors -= e.errors * errorWeight
+	}
+}
+
+// errorWeight is the weight of each response in the error rate. Unlike
+// latency, which is weighted by time, the error rate must react to a single
+// failure however busy the endpoint is.
+const errorWeight = 0.2
+
+// score returns the expected cost of sending the endpoint another request.
+// Lower is better.
+func (pe *PeakEWMA) score(t *trackedEndpoint, now time.Time) float64 {
+	var (
+		inflight = float64(t.outstanding())
+		e        = t.ewma
+	)
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	w := pe.decayLocked(e, now)
+	e.latency *= w
+	e.errors *= w
+	if e.latency == 0 && inflight > 0 {
+		// No responses yet; don't send everything to a new endpoint.
+		return pe.penalty + inflight
+	}
+	return (e.latency + e.errors*pe.penalty) * (inflight + 1)
+}
+
+// decayLocked returns the weight that the averages keep since the last
+// update, and advances the stamp.
+func (pe *PeakEWMA) decayLocked(e *ewma, now time.Time) float64 {
+	elapsed := float64(now.Sub(e.stamp))
+	if elapsed <= 0 {
+		return 1
+	}
+	e.stamp = now
+	return math.Exp(-elapsed / pe.decay)
+}
@@ -0,0 +1,159 @@
+package loadbalancer_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
+	"github.com/go-kit/kit/loadbalancer/fixed"
+	"github.com/go-kit/kit/log"
+)
+
+// fakePeers returns endpoints that advance the clock by their latency, and
+// fail if their error is set.
+type fakePeers struct {
+	now       time.Time
+	latencies []time.Duration
+	errs      []error
+	counts    []int
+}
+
+func newFakePeers(latencies ...time.Duration) *fakePeers {
+	return &fakePeers{
+		now:       time.Unix(1000, 0),
+		latencies: latencies,
+		errs:      make([]error, len(latencies)),
+		counts:    make([]int, len(latencies)),
+	}
+}
+
+func (f *fakePeers) clock() time.Time { return f.now }
+
+func (f *fakePeers) endpoints() []endpoint.Endpoint {
+	endpoints := make([]endpoint.Endpoint, len(f.latencies))
+	for i := range f.latencies {
+		i0 := i
+		endpoints[i] = func(context.Context, interface{}) (interface{}, error) {
+			f.counts[i0]++
+			f.now = f.now.Add(f.latencies[i0])
+			return struct{}{}, f.errs[i0]
+		}
+	}
+	return endpoints
+}
+
+func (f *fakePeers) run(t *testing.T, lb loadbalancer.LoadBalancer, n int) {
+	for i := range f.counts {
+		f.counts[i] = 0
+	}
+	for i := 0; i < n; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e(context.Background(), struct{}{})
+	}
+}
+
+func TestPeakEWMAPrefersFastEndpoints(t *testing.T) {
+	var (
+		f  = newFakePeers(time.Millisecond, 100*time.Millisecond)
+		lb = loadbalancer.NewPeakEWMA(fixed.NewPublisher(f.endpoints()), 123, loadbalancer.EWMAClock(f.clock))
+	)
+	f.run(t, lb, 2) // both endpoints have no score yet
+	f.run(t, lb, 100)
+	if want, have := 100, f.counts[0]; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Left alone, the slow endpoint's score decays below the fast one's.
+	f.now = f.now.Add(time.Minute)
+	f.run(t, lb, 2)
+	if want, have := 1, f.counts[1]; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestPeakEWMAPenalizesFailures(t *testing.T) {
+	var (
+		f  = newFakePeers(10*time.Millisecond, 10*time.Millisecond)
+		lb = loadbalancer.NewPeakEWMA(fixed.NewPublisher(f.endpoints()), 123,
+			loadbalancer.EWMAClock(f.clock),
+			loadbalancer.FailurePenalty(time.Second),
+		)
+	)
+	f.errs[1] = errors.New("failure")
+	f.run(t, lb, 100)
+	if have := f.counts[1]; have > 2 {
+		t.Errorf("failing endpoint got %d of 100 requests", have)
+	}
+
+	// Left alone, the failures are forgotten, and the endpoint is retried.
+	f.errs[1] = nil
+	f.now = f.now.Add(time.Minute)
+	f.run(t, lb, 100)
+	if have := f.counts[1]; have == 0 {
+		t.Errorf("recovered endpoint got %d of 100 requests", have)
+	}
+}
+
+func TestPeakEWMAKeepsScoresAcrossReplace(t *testing.T) {
+	var (
+		f       = newFakePeers(time.Millisecond, 100*time.Millisecond)
+		peers   = f.endpoints()
+		factory = func(instance string) (endpoint.Endpoint, io.Closer, error) {
+			peer, c := peers[map[string]int{"fast": 0, "slow": 1}[instance]], new(bool)
+			e := func(ctx context.Context, request interface{}) (interface{}, error) {
+				if *c {
+					return nil, errors.New("closed endpoint")
+				}
+				return peer(ctx, request)
+			}
+			return e, closerFunc(func() error { *c = true; return nil }), nil
+		}
+		cache = loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+		lb    = loadbalancer.NewPeakEWMA(cache, 123, loadbalancer.EWMAClock(f.clock))
+	)
+	cache.Replace([]string{"fast", "slow"})
+	f.run(t, lb, 2) // both endpoints have no score yet
+
+	// Refreshing the publisher with the same instances keeps their scores.
+	for i := 0; i < 10; i++ {
+		cache.Replace([]string{"fast", "slow"})
+		f.run(t, lb, 1)
+		if want, have := 0, f.counts[1]; want != have {
+			t.Fatalf("after %d refreshes: want %d requests to the slow endpoint, have %d", i+1, want, have)
+		}
+	}
+
+	// Removing the fast instance and adding it again closes its endpoint;
+	// its score is kept, but requests go to the new endpoint.
+	cache.Replace([]string{"slow"})
+	cache.Replace([]string{"fast", "slow"})
+	f.run(t, lb, 3)
+	if want, have := 3, f.counts[0]; want != have {
+		t.Errorf("after a flap: want %d requests to the fast endpoint, have %d", want, have)
+	}
+	if want, have := 0, f.counts[1]; want != have {
+		t.Errorf("after a flap: want %d requests to the slow endpoint, have %d", want, have)
+	}
+}
+
+func TestPeakEWMAKeepsCountsAcrossReplace(t *testing.T) {
+	testKeepsCountsAcrossReplace(t, func(p loadbalancer.Publisher) loadbalancer.LoadBalancer {
+		return loadbalancer.NewPeakEWMA(p, 123)
+	})
+}
+
+func TestPeakEWMANoEndpoints(t *testing.T) {
+	lb := loadbalancer.NewPeakEWMA(fixed.NewPublisher([]endpoint.Endpoint{}), 123)
+	_, have := lb.Endpoint()
+	if want := loadbalancer.ErrNoEndpoints; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
@@ -8,3 +8,36 @@ import "github.com/go-kit/kit/endpoint"
 type Publisher interface {
 	Endpoints() ([]endpoint.Endpoint, error)
 }
+
+// InstancePublisher is a Publisher that also yields the instance string each
+// endpoint was made from. Load balancers that need a stable identity for
+// endpoints, such as ConsistentHash, require it.
+type InstancePublisher interface {
+	Publisher
+	InstanceEndpoints() ([]InstanceEndpoint, error)
+}
+
+// InstanceEndpoint is an endpoint, together with the instance string it was
+// made from and the instance's metadata.
+type InstanceEndpoint struct {
+	Instance string
+	Endpoint endpoint.Endpoint
+	Metadata Metadata
+}
+
+// Metadata describes an instance, as reported by service discovery.
+type Metadata struct {
+	// Weight is the instance's share of traffic, relative to the other
+	// instances with the same priority. As in DNS SRV records, instances with
+	// weight 0 get a very small share, unless all instances with their
+	// priority have weight 0.
+	Weight int
+
+	// Priority orders instances into tiers, as in DNS SRV records. Only the
+	// lowest-numbered tier with any instances gets traffic.
+	Priority int
+}
+
+// DefaultMetadata is the metadata of instances that service discovery says
+// nothing about.
+var DefaultMetadata = Metadata{Weight: 1}
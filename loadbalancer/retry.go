@@ -14,10 +14,22 @@ import (
 // Requests to the endpoint will be automatically load balanced via the load
 // balancer. Requests that return errors will be retried until they succeed,
 // up to max times, or until the timeout is elapsed, whichever comes first.
+//
+// If the load balancer also implements ContextLoadBalancer, endpoints are
+// chosen for each request, as with RetryContext.
 func Retry(max int, timeout time.Duration, lb LoadBalancer) endpoint.Endpoint {
 	if lb == nil {
 		panic("nil LoadBalancer")
 	}
+	return RetryContext(max, timeout, contextLoadBalancer(lb))
+}
+
+// RetryContext is like Retry, but chooses the endpoint for each attempt from
+// the request and its context, which carries the timeout.
+func RetryContext(max int, timeout time.Duration, lb ContextLoadBalancer) endpoint.Endpoint {
+	if lb == nil {
+		panic("nil ContextLoadBalancer")
+	}
 
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		var (
@@ -29,7 +41,7 @@ func Retry(max int, timeout time.Duration, lb LoadBalancer) endpoint.Endpoint {
 		defer cancel()
 		for i := 1; i <= max; i++ {
 			go func() {
-				e, err := lb.Endpoint()
+				e, err := lb.EndpointFor(newctx, request)
 				if err != nil {
 					errs <- err
 					return
@@ -81,3 +81,51 @@ func TestRetryTimeout(t *testing.T) {
 		t.Errorf("wanted %v, got none", context.DeadlineExceeded)
 	}
 }
+
+func TestRetryContext(t *testing.T) {
+	type tenantRequest struct{ tenant int }
+	var (
+		endpoints = []endpoint.Endpoint{
+			func(context.Context, interface{}) (interface{}, error) { return "zero", nil },
+			func(context.Context, interface{}) (interface{}, error) { return "one", nil },
+		}
+		key = "key"
+		lb  = loadbalancer.ContextLoadBalancerFunc(func(ctx context.Context, request interface{}) (endpoint.Endpoint, error) {
+			if want, have := "value", ctx.Value(key); want != have {
+				t.Errorf("want %v, have %v", want, have)
+			}
+			return endpoints[request.(tenantRequest).tenant%len(endpoints)], nil
+		})
+		retry = loadbalancer.RetryContext(1, time.Second, lb)
+		ctx   = context.WithValue(context.Background(), key, "value")
+	)
+	for tenant, want := range []string{"zero", "one", "zero"} {
+		have, err := retry(ctx, tenantRequest{tenant})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want != have {
+			t.Errorf("tenant %d: want %v, have %v", tenant, want, have)
+		}
+	}
+
+	// Retry uses the ContextLoadBalancer of a LoadBalancer that has one.
+	if _, err := loadbalancer.Retry(1, time.Second, loadbalancer.BackgroundAdapter{lb})(ctx, tenantRequest{1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdapters(t *testing.T) {
+	var (
+		e        = func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+		lb       = loadbalancer.NewRoundRobin(fixed.NewPublisher([]endpoint.Endpoint{e}))
+		adapted  = loadbalancer.ContextAdapter{lb}
+		restored = loadbalancer.BackgroundAdapter{adapted}
+	)
+	if _, err := adapted.EndpointFor(context.Background(), struct{}{}); err != nil {
+		t.Error(err)
+	}
+	if _, err := restored.Endpoint(); err != nil {
+		t.Error(err)
+	}
+}
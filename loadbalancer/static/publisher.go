@@ -8,12 +8,16 @@ import (
 )
 
 // Publisher yields a set of static endpoints as produced by the passed factory.
-type Publisher struct{ publisher *fixed.Publisher }
+type Publisher struct {
+	publisher *fixed.Publisher
+	ies       []loadbalancer.InstanceEndpoint
+}
 
 // NewPublisher returns a static endpoint Publisher.
 func NewPublisher(instances []string, factory loadbalancer.Factory, logger log.Logger) Publisher {
 	logger = log.NewContext(logger).With("component", "Static Publisher")
 	endpoints := []endpoint.Endpoint{}
+	ies := []loadbalancer.InstanceEndpoint{}
 	for _, instance := range instances {
 		e, _, err := factory(instance) // never close
 		if err != nil {
@@ -21,11 +25,17 @@ func NewPublisher(instances []string, factory loadbalancer.Factory, logger log.L
 			continue
 		}
 		endpoints = append(endpoints, e)
+		ies = append(ies, loadbalancer.InstanceEndpoint{Instance: instance, Endpoint: e, Metadata: loadbalancer.DefaultMetadata})
 	}
-	return Publisher{publisher: fixed.NewPublisher(endpoints)}
+	return Publisher{publisher: fixed.NewPublisher(endpoints), ies: ies}
 }
 
 // Endpoints implements Publisher.
 func (p Publisher) Endpoints() ([]endpoint.Endpoint, error) {
 	return p.publisher.Endpoints()
 }
+
+// InstanceEndpoints implements InstancePublisher.
+func (p Publisher) InstanceEndpoints() ([]loadbalancer.InstanceEndpoint, error) {
+	return p.ies, nil
+}
@@ -0,0 +1,163 @@
+package loadbalancer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// tracker wraps the endpoints of a publisher to count their in-flight
+// requests, and more, for load balancers that pick endpoints by load. The wrappers are
+// kept by instance string, so that publishers which yield a fresh set of the
+// same instances, like the ones built on EndpointCache, don't reset the
+// counts. A kept wrapper always forwards to the current endpoint of its
+// instance, which may have been recreated in the meantime, e.g. after the
+// instance was removed and added again. Endpoints of plain publishers have no
+// instance strings, and their counts are reset whenever the publisher yields a
+// different slice.
+type tracker struct {
+	p       InstancePublisher
+	newNode func(next endpoint.Endpoint) *trackedEndpoint
+
+	mtx   sync.Mutex   // serializes rebuilds
+	state atomic.Value // *trackedSet
+}
+
+func newTracker(p Publisher, newNode func(endpoint.Endpoint) *trackedEndpoint) *tracker {
+	return &tracker{p: instancePublisher(p), newNode: newNode}
+}
+
+type trackedSet struct {
+	source     []InstanceEndpoint
+	nodes      []*trackedEndpoint
+	byInstance map[string]*trackedEndpoint
+}
+
+type trackedEndpoint struct {
+	inflight int64
+	next     atomic.Value // endpoint.Endpoint
+	endpoint endpoint.Endpoint
+	ewma     *ewma // only kept by PeakEWMA
+}
+
+func (t *trackedEndpoint) outstanding() int64 {
+	return atomic.LoadInt64(&t.inflight)
+}
+
+// forward calls the current endpoint of the instance.
+func (t *trackedEndpoint) forward(ctx context.Context, request interface{}) (interface{}, error) {
+	return t.next.Load().(endpoint.Endpoint)(ctx, request)
+}
+
+func newTrackedEndpoint(next endpoint.Endpoint) *trackedEndpoint {
+	t := &trackedEndpoint{}
+	t.next.Store(next)
+	t.endpoint = func(ctx context.Context, request interface{}) (interface{}, error) {
+		atomic.AddInt64(&t.inflight, 1)
+		defer atomic.AddInt64(&t.inflight, -1)
+		return t.forward(ctx, request)
+	}
+	return t
+}
+
+// nodes returns the tracked endpoints for the current endpoints of the
+// publisher.
+func (t *tracker) nodes() ([]*trackedEndpoint, error) {
+	ies, err := t.p.InstanceEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := t.state.Load().(*trackedSet); ok && sameInstanceEndpoints(s.source, ies) {
+		return s.nodes, nil
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	prev, _ := t.state.Load().(*trackedSet)
+	if prev != nil && sameInstanceEndpoints(prev.source, ies) {
+		return prev.nodes, nil
+	}
+	s := &trackedSet{
+		source:     ies,
+		nodes:      make([]*trackedEndpoint, len(ies)),
+		byInstance: make(map[string]*trackedEndpoint, len(ies)),
+	}
+	for i, ie := range ies {
+		var node *trackedEndpoint
+		if prev != nil && ie.Instance != "" {
+			node = prev.byInstance[ie.Instance]
+		}
+		if node == nil {
+			node = t.newNode(ie.Endpoint)
+		} else {
+			node.next.Store(ie.Endpoint)
+		}
+		s.nodes[i] = node
+		if ie.Instance != "" {
+			s.byInstance[ie.Instance] = node
+		}
+	}
+	t.state.Store(s)
+	return s.nodes, nil
+}
+
+// sameInstanceEndpoints reports whether a and b are the same slice. Load
+// balancers use it to skip rebuilding their per-endpoint state on every
+// request. It's only a shortcut: publishers may yield a new slice of the
+// same instances at any time, so rebuilds must carry state over by instance.
+func sameInstanceEndpoints(a, b []InstanceEndpoint) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// instancePublisher returns p as an InstancePublisher. If p is a plain
+// Publisher, the instance strings are empty.
+func instancePublisher(p Publisher) InstancePublisher {
+	if ip, ok := p.(InstancePublisher); ok {
+		return ip
+	}
+	return &anonymousPublisher{Publisher: p}
+}
+
+// anonymousPublisher adapts a plain Publisher to InstancePublisher. It
+// converts each slice of endpoints once, so that the result is the same
+// slice for as long as the publisher's is.
+type anonymousPublisher struct {
+	Publisher
+	mtx     sync.Mutex   // serializes conversions
+	current atomic.Value // *anonymousSet
+}
+
+type anonymousSet struct {
+	source []endpoint.Endpoint
+	ies    []InstanceEndpoint
+}
+
+func (p *anonymousPublisher) InstanceEndpoints() ([]InstanceEndpoint, error) {
+	endpoints, err := p.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := p.current.Load().(*anonymousSet); ok && sameEndpoints(s.source, endpoints) {
+		return s.ies, nil
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if s, ok := p.current.Load().(*anonymousSet); ok && sameEndpoints(s.source, endpoints) {
+		return s.ies, nil
+	}
+	s := &anonymousSet{source: endpoints, ies: make([]InstanceEndpoint, len(endpoints))}
+	for i, e := range endpoints {
+		s.ies[i] = InstanceEndpoint{Endpoint: e, Metadata: DefaultMetadata}
+	}
+	p.current.Store(s)
+	return s.ies, nil
+}
+
+// sameEndpoints reports whether a and b are the same slice.
+func sameEndpoints(a, b []endpoint.Endpoint) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
@@ -0,0 +1,123 @@
+package loadbalancer
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// WeightedRoundRobin is a load balancer that returns each of the published
+// endpoints in proportion to the weight in its metadata, spread out as evenly
+// as possible, as in nginx: weights 5, 1 and 1 yield a a b a c a a.
+//
+// Only the instances with the lowest-numbered priority get traffic. Instances
+// with a higher-numbered priority are fallbacks, used when there are no
+// instances with a lower one, as with DNS SRV records.
+type WeightedRoundRobin struct {
+	p InstancePublisher
+
+	mtx    sync.Mutex
+	source []InstanceEndpoint
+	peers  []weightedPeer
+	total  int
+}
+
+type weightedPeer struct {
+	instance string
+	endpoint endpoint.Endpoint
+	weight   int
+	current  int
+}
+
+// NewWeightedRoundRobin returns a new WeightedRoundRobin load balancer.
+func NewWeightedRoundRobin(p InstancePublisher) *WeightedRoundRobin {
+	return &WeightedRoundRobin{p: p}
+}
+
+// Endpoint implements the LoadBalancer interface.
+func (wrr *WeightedRoundRobin) Endpoint() (endpoint.Endpoint, error) {
+	ies, err := wrr.p.InstanceEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	wrr.mtx.Lock()
+	defer wrr.mtx.Unlock()
+
+	if !sameInstanceEndpoints(wrr.source, ies) {
+		wrr.update(ies)
+	}
+	if len(wrr.peers) <= 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	// Every peer gains its weight, and the one that's furthest ahead is
+	// picked and set back by the total.
+	best := 0
+	for i := range wrr.peers {
+		wrr.peers[i].current += wrr.peers[i].weight
+		if wrr.peers[i].current > wrr.peers[best].current {
+			best = i
+		}
+	}
+	wrr.peers[best].current -= wrr.total
+	return wrr.peers[best].endpoint, nil
+}
+
+// update replaces the peers with the top priority tier of ies. Peers that
+// remain keep their position in the rotation.
+func (wrr *WeightedRoundRobin) update(ies []InstanceEndpoint) {
+	current := make(map[string]int, len(wrr.peers))
+	for _, peer := range wrr.peers {
+		current[peer.instance] = peer.current
+	}
+
+	var tier []InstanceEndpoint
+	for _, ie := range ies {
+		switch {
+		case len(tier) == 0 || ie.Metadata.Priority < tier[0].Metadata.Priority:
+			tier = []InstanceEndpoint{ie}
+		case ie.Metadata.Priority == tier[0].Metadata.Priority:
+			tier = append(tier, ie)
+		}
+	}
+
+	// As in RFC 2782, instances with weight 0 get a small share of traffic
+	// when others in their tier have weights, and an equal share otherwise.
+	var zeros, weighted bool
+	for _, ie := range tier {
+		if ie.Metadata.Weight > 0 {
+			weighted = true
+		} else {
+			zeros = true
+		}
+	}
+	scale := 1
+	if zeros && weighted {
+		scale = zeroWeightScale
+	}
+
+	var (
+		peers = make([]weightedPeer, len(tier))
+		total = 0
+	)
+	for i, ie := range tier {
+		weight := ie.Metadata.Weight * scale
+		if weight <= 0 {
+			weight = 1
+		}
+		peers[i] = weightedPeer{
+			instance: ie.Instance,
+			endpoint: ie.Endpoint,
+			weight:   weight,
+			current:  current[ie.Instance],
+		}
+		total += weight
+	}
+
+	wrr.source, wrr.peers, wrr.total = ies, peers, total
+}
+
+// zeroWeightScale is how many times more traffic an instance with weight 1
+// gets than one with weight 0, in the same tier.
+const zeroWeightScale = 100
@@ -0,0 +1,108 @@
+package loadbalancer_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/loadbalancer"
+	"github.com/go-kit/kit/log"
+)
+
+func TestWeightedRoundRobinSmooth(t *testing.T) {
+	cache := newInstanceCache()
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"a": {Weight: 5},
+		"b": {Weight: 1},
+		"c": {Weight: 1},
+	})
+	lb := loadbalancer.NewWeightedRoundRobin(cache)
+
+	if want, have := "aabacaa aabacaa", sequence(t, lb, 7)+" "+sequence(t, lb, 7); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// Refreshing the same instances doesn't restart the rotation.
+	sequence(t, lb, 3)
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"a": {Weight: 5},
+		"b": {Weight: 1},
+		"c": {Weight: 1},
+	})
+	if want, have := "acaa", sequence(t, lb, 4); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWeightedRoundRobinZeroWeights(t *testing.T) {
+	cache := newInstanceCache()
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"a": {Weight: 0},
+		"b": {Weight: 2},
+	})
+	lb := loadbalancer.NewWeightedRoundRobin(cache)
+
+	// Weight 0 instances get a small share, as in RFC 2782.
+	if want, have := 1, strings.Count(sequence(t, lb, 201), "a"); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"a": {Weight: 0},
+		"b": {Weight: 0},
+	})
+	if want, have := 2, strings.Count(sequence(t, lb, 4), "a"); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestWeightedRoundRobinPriorities(t *testing.T) {
+	cache := newInstanceCache()
+	lb := loadbalancer.NewWeightedRoundRobin(cache)
+	if _, have := lb.Endpoint(); have != loadbalancer.ErrNoEndpoints {
+		t.Fatalf("want %v, have %v", loadbalancer.ErrNoEndpoints, have)
+	}
+
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"a": {Weight: 1, Priority: 10},
+		"b": {Weight: 1, Priority: 10},
+		"c": {Weight: 1, Priority: 20},
+	})
+	if want, have := "abab", sequence(t, lb, 4); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// The fallback tier is only used when the preferred one is empty.
+	cache.ReplaceWithMetadata(map[string]loadbalancer.Metadata{
+		"c": {Weight: 1, Priority: 20},
+	})
+	if want, have := "cc", sequence(t, lb, 2); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+// newInstanceCache returns an EndpointCache whose endpoints return their
+// instance string.
+func newInstanceCache() *loadbalancer.EndpointCache {
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		return func(context.Context, interface{}) (interface{}, error) { return instance, nil }, nil, nil
+	}
+	return loadbalancer.NewEndpointCache(factory, log.NewNopLogger())
+}
+
+// sequence returns the instances of the next n endpoints of lb.
+func sequence(t *testing.T, lb loadbalancer.LoadBalancer, n int) string {
+	var s string
+	for i := 0; i < n; i++ {
+		e, err := lb.Endpoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		instance, _ := e(context.Background(), struct{}{})
+		s += instance.(string)
+	}
+	return s
+}
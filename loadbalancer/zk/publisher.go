@@ -77,6 +77,11 @@ func (p *Publisher) Endpoints() ([]endpoint.Endpoint, error) {
 	return p.cache.Endpoints()
 }
 
+// InstanceEndpoints implements the InstancePublisher interface.
+func (p *Publisher) InstanceEndpoints() ([]loadbalancer.InstanceEndpoint, error) {
+	return p.cache.InstanceEndpoints()
+}
+
 // Stop terminates the Publisher.
 func (p *Publisher) Stop() {
 	close(p.quit)
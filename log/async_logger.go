@@ -0,0 +1,170 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAsyncLoggerClosed is returned by the Log method of an AsyncLogger that
+// has been closed.
+var ErrAsyncLoggerClosed = errors.New("async logger closed")
+
+// OverflowPolicy determines what an AsyncLogger does with a log event when
+// its buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block makes Log wait until there is room in the buffer. No log events
+	// are lost, but a slow writer stalls the goroutines that log.
+	Block OverflowPolicy = iota
+
+	// DropNewest discards the event being logged.
+	DropNewest
+
+	// DropOldest discards the oldest buffered event, to make room for the
+	// event being logged.
+	DropOldest
+)
+
+// AsyncLogger queues log events and passes them to another logger from a
+// background goroutine, so that a slow writer doesn't stall the goroutines
+// that log. Errors returned by the wrapped logger are discarded.
+type AsyncLogger struct {
+	next    Logger
+	policy  OverflowPolicy
+	dropped uint64
+
+	// mtx guards closed, and ensures no event is sent on eventc once it's
+	// closed.
+	mtx    sync.RWMutex
+	closed bool
+
+	eventc chan []interface{}
+	flushc chan chan struct{}
+	donec  chan struct{}
+}
+
+// NewAsyncLogger returns a new AsyncLogger that buffers up to bufferSize log
+// events for next, and applies policy when the buffer is full. A bufferSize
+// less than 1 is treated as 1, as DropOldest needs a buffered event to drop.
+// Callers must Close the AsyncLogger to release its goroutine and write the
+// buffered events.
+func NewAsyncLogger(next Logger, bufferSize int, policy OverflowPolicy) *AsyncLogger {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	l := &AsyncLogger{
+		next:   next,
+		policy: policy,
+		eventc: make(chan []interface{}, bufferSize),
+		flushc: make(chan chan struct{}),
+		donec:  make(chan struct{}),
+	}
+	go l.loop()
+	return l
+}
+
+// Log implements Logger. It queues a copy of keyvals, and returns before the
+// event is written.
+func (l *AsyncLogger) Log(keyvals ...interface{}) error {
+	kvs := make([]interface{}, len(keyvals))
+	copy(kvs, keyvals)
+
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if l.closed {
+		return ErrAsyncLoggerClosed
+	}
+
+	switch l.policy {
+	case DropNewest:
+		select {
+		case l.eventc <- kvs:
+		default:
+			atomic.AddUint64(&l.dropped, 1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case l.eventc <- kvs:
+				return nil
+			default:
+			}
+			select {
+			case <-l.eventc:
+				atomic.AddUint64(&l.dropped, 1)
+			default:
+			}
+		}
+	default:
+		l.eventc <- kvs
+	}
+	return nil
+}
+
+// Dropped returns the number of log events discarded so far because the
+// buffer was full.
+func (l *AsyncLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+// Flush blocks until all log events queued before the call have been passed
+// to the wrapped logger.
+func (l *AsyncLogger) Flush() {
+	l.mtx.RLock()
+	if l.closed {
+		l.mtx.RUnlock()
+		<-l.donec
+		return
+	}
+	flushed := make(chan struct{})
+	l.flushc <- flushed
+	l.mtx.RUnlock()
+	<-flushed
+}
+
+// Close writes all buffered log events and stops the background goroutine.
+// Subsequent calls to Log return ErrAsyncLoggerClosed. Close is idempotent.
+func (l *AsyncLogger) Close() error {
+	l.mtx.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.eventc)
+	}
+	l.mtx.Unlock()
+	<-l.donec
+	return nil
+}
+
+func (l *AsyncLogger) loop() {
+	defer close(l.donec)
+	for {
+		select {
+		case kvs, ok := <-l.eventc:
+			if !ok {
+				return
+			}
+			l.next.Log(kvs...)
+		case flushed := <-l.flushc:
+			l.drain()
+			close(flushed)
+		}
+	}
+}
+
+// drain writes the events currently in the buffer. Events queued meanwhile
+// are left for the loop, so that a busy logger can't delay Flush forever.
+func (l *AsyncLogger) drain() {
+	for n := len(l.eventc); n > 0; n-- {
+		select {
+		case kvs, ok := <-l.eventc:
+			if !ok {
+				return
+			}
+			l.next.Log(kvs...)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package log_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestAsyncLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.NewAsyncLogger(log.NewLogfmtLogger(buf), 10, log.Block)
+
+	kvs := []interface{}{"a", 1}
+	logger.Log(kvs...)
+	kvs[1] = 2 // Log should copy its keyvals
+	logger.Log("b", 2)
+	logger.Flush()
+	if want, have := "a=1\nb=2\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	logger.Log("c", 3)
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "a=1\nb=2\nc=3\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := log.ErrAsyncLoggerClosed, logger.Log("d", 4); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	logger.Flush() // should not block after Close
+	logger.Close() // should be idempotent
+}
+
+func TestAsyncLoggerOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		policy log.OverflowPolicy
+		want   string
+	}{
+		{log.DropNewest, "n=0\nn=1\nn=2\n"},
+		{log.DropOldest, "n=0\nn=3\nn=4\n"},
+	} {
+		buf := &bytes.Buffer{}
+		next, release := blockingLogger(log.NewLogfmtLogger(buf))
+		logger := log.NewAsyncLogger(next, 2, tc.policy)
+
+		logger.Log("n", 0) // taken by the background goroutine, which blocks
+		<-next.started
+		for i := 1; i < 5; i++ {
+			logger.Log("n", i)
+		}
+		if want, have := uint64(2), logger.Dropped(); want != have {
+			t.Errorf("policy %d: want %d dropped, have %d", tc.policy, want, have)
+		}
+		release()
+		logger.Close()
+		if have := buf.String(); tc.want != have {
+			t.Errorf("policy %d: want %q, have %q", tc.policy, tc.want, have)
+		}
+	}
+}
+
+func TestAsyncLoggerZeroBuffer(t *testing.T) {
+	buf := &bytes.Buffer{}
+	next, release := blockingLogger(log.NewLogfmtLogger(buf))
+	logger := log.NewAsyncLogger(next, 0, log.DropOldest)
+
+	logger.Log("n", 0) // taken by the background goroutine, which blocks
+	<-next.started
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i < 5; i++ {
+			logger.Log("n", i)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Log didn't return with a zero buffer size")
+	}
+	if want, have := uint64(3), logger.Dropped(); want != have {
+		t.Errorf("want %d dropped, have %d", want, have)
+	}
+	release()
+	logger.Close()
+	if want, have := "n=0\nn=4\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestAsyncLoggerConcurrency(t *testing.T) {
+	logger := log.NewAsyncLogger(log.NewLogfmtLogger(&bytes.Buffer{}), 100, log.DropOldest)
+	defer logger.Close()
+	testConcurrency(t, logger)
+}
+
+type gatedLogger struct {
+	log.Logger
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+// blockingLogger returns a logger that signals started on the first event,
+// and blocks until release is called.
+func blockingLogger(next log.Logger) (*gatedLogger, func()) {
+	l := &gatedLogger{Logger: next, started: make(chan struct{}), gate: make(chan struct{})}
+	return l, func() { close(l.gate) }
+}
+
+func (l *gatedLogger) Log(keyvals ...interface{}) error {
+	l.once.Do(func() { close(l.started) })
+	<-l.gate
+	return l.Logger.Log(keyvals...)
+}
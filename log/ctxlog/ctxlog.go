@@ -0,0 +1,52 @@
+// Package ctxlog derives log contexts carrying request-scoped values, like a
+// trace or request ID, from a context.Context. It's kept apart from package
+// log, so that the core logging package doesn't depend on package context.
+package ctxlog
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/log"
+)
+
+// An Extractor returns keyvals derived from request-scoped values carried by
+// a context.Context. It returns nil if ctx carries no such values.
+type Extractor func(ctx context.Context) []interface{}
+
+// Logger is a log context with a set of extractors.
+type Logger struct {
+	logger     *log.Context
+	extractors []Extractor
+}
+
+// New returns a Logger that logs to logger, with the keyvals returned by
+// extractors added by WithContext.
+func New(logger log.Logger, extractors ...Extractor) *Logger {
+	return &Logger{
+		logger:     log.NewContext(logger),
+		extractors: extractors,
+	}
+}
+
+// Log implements log.Logger, without any request-scoped keyvals.
+func (l *Logger) Log(keyvals ...interface{}) error {
+	return l.logger.Log(keyvals...)
+}
+
+// WithContext returns a new log context with the keyvals returned by the
+// extractors, in order, appended to those of the Logger. Endpoint
+// middlewares can call it with the request context to include request-scoped
+// fields in their log events. The keyvals of each extractor are padded with
+// log.ErrMissingValue if needed, so that an extractor returning an odd
+// number of them doesn't shift the keyvals that follow.
+func (l *Logger) WithContext(ctx context.Context) *log.Context {
+	var keyvals []interface{}
+	for _, extract := range l.extractors {
+		kvs := extract(ctx)
+		keyvals = append(keyvals, kvs...)
+		if len(kvs)%2 != 0 {
+			keyvals = append(keyvals, log.ErrMissingValue)
+		}
+	}
+	return l.logger.With(keyvals...)
+}
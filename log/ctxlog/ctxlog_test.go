@@ -0,0 +1,58 @@
+package ctxlog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/ctxlog"
+)
+
+func TestWithContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	type key int
+	userExtractor := func(ctx context.Context) []interface{} {
+		if user, ok := ctx.Value(key(0)).(string); ok {
+			return []interface{}{"user", user}
+		}
+		return nil
+	}
+	traceExtractor := func(ctx context.Context) []interface{} {
+		return []interface{}{"trace", ctx.Value(key(1))}
+	}
+	logger := ctxlog.New(log.NewContext(log.NewLogfmtLogger(buf)).With("a", 1), userExtractor, traceExtractor)
+
+	ctx := context.WithValue(context.Background(), key(0), "alice")
+	ctx = context.WithValue(ctx, key(1), "abc")
+	if err := logger.WithContext(ctx).Log("msg", "message"); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "a=1 user=alice trace=abc msg=message\n", buf.String(); want != have {
+		t.Errorf("\nwant: %shave: %s", want, have)
+	}
+
+	buf.Reset()
+	if err := logger.WithContext(context.Background()).Log("msg", "message"); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "a=1 trace=null msg=message\n", buf.String(); want != have {
+		t.Errorf("\nwant: %shave: %s", want, have)
+	}
+}
+
+func TestWithContextOddKeyvals(t *testing.T) {
+	buf := &bytes.Buffer{}
+	odd := func(context.Context) []interface{} { return []interface{}{"odd"} }
+	even := func(context.Context) []interface{} { return []interface{}{"k", "v"} }
+	logger := ctxlog.New(log.NewLogfmtLogger(buf), odd, even)
+
+	if err := logger.WithContext(context.Background()).Log("msg", "message"); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "odd=(MISSING) k=v msg=message\n", buf.String(); want != have {
+		t.Errorf("\nwant: %shave: %s", want, have)
+	}
+}
@@ -0,0 +1,34 @@
+// Package keyval holds helpers shared by the loggers that format keyvals
+// themselves rather than through an encoding package.
+package keyval
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// String formats v as a log value. Errors and Stringers are formatted with
+// their Error and String methods; if a method panics because v is a nil
+// pointer, as logfmt and JSON loggers allow, String returns "null" instead.
+func String(v interface{}) (s string) {
+	defer func() {
+		if panicVal := recover(); panicVal != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				s = "null"
+			} else {
+				panic(panicVal)
+			}
+		}
+	}()
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return x
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
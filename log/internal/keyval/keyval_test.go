@@ -0,0 +1,46 @@
+package keyval_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-kit/kit/log/internal/keyval"
+)
+
+type pathError struct{ path string }
+
+func (e *pathError) Error() string { return "bad path " + e.path }
+
+type point struct{ x, y int }
+
+func (p *point) String() string { return "point" }
+
+type name struct{ s string }
+
+func (n *name) String() string { return n.s }
+
+func TestString(t *testing.T) {
+	var (
+		nilErr  *pathError
+		nilName *name
+		nilPt   *point
+	)
+	for _, tc := range []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "null"},
+		{"s", "s"},
+		{42, "42"},
+		{errors.New("boom"), "boom"},
+		{&pathError{"/x"}, "bad path /x"},
+		{nilErr, "null"},
+		{&name{"n"}, "n"},
+		{nilName, "null"},
+		{nilPt, "point"},
+	} {
+		if have := keyval.String(tc.v); tc.want != have {
+			t.Errorf("%#v: want %q, have %q", tc.v, tc.want, have)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package journald provides a logger that writes to the systemd journal,
+// using its native protocol, with each keyval as a journal field.
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/internal/keyval"
+	"github.com/go-kit/kit/log/levels"
+	"github.com/go-kit/kit/log/syslog"
+)
+
+// SocketPath is the path of the journal's native protocol socket.
+const SocketPath = "/run/systemd/journal/socket"
+
+// Priority is a journal priority, which uses the syslog severities.
+type Priority int
+
+// Journal priorities.
+const (
+	PriEmerg Priority = iota
+	PriAlert
+	PriCrit
+	PriErr
+	PriWarning
+	PriNotice
+	PriInfo
+	PriDebug
+)
+
+// LevelPriority maps a level of package levels to a journal priority, the
+// same way syslog.LevelSeverity does.
+func LevelPriority(level levels.Level) Priority {
+	return Priority(syslog.LevelSeverity(level))
+}
+
+// Dial connects to the journal's native protocol socket.
+func Dial() (net.Conn, error) {
+	return net.Dial("unixgram", SocketPath)
+}
+
+type journaldLogger struct {
+	w          io.Writer
+	identifier string
+	levelKey   string
+	messageKey string
+
+	mtx sync.Mutex
+}
+
+// NewLogger returns a Logger that writes each log event to w as a journal
+// entry, in a single Write call, so that w may be the datagram socket
+// returned by Dial. Entries must fit in a single datagram; the journal's
+// support for larger entries, passed as file descriptors, isn't implemented.
+//
+// The value of the message key becomes the MESSAGE field, and the value of
+// the level key, as written by package levels, sets the PRIORITY field;
+// events without a level get PriNotice. Every keyval also becomes a field,
+// named after its key in upper case, with characters other than letters,
+// digits and underscores replaced by underscores. Keys that would collide with
+// the MESSAGE, PRIORITY or SYSLOG_IDENTIFIER fields written by the logger get
+// a KEY_ prefix.
+func NewLogger(w io.Writer, options ...Option) log.Logger {
+	l := &journaldLogger{
+		w:          w,
+		identifier: filepath.Base(os.Args[0]),
+		levelKey:   "level",
+		messageKey: "msg",
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Option sets a parameter for the journald logger.
+type Option func(*journaldLogger)
+
+// Identifier sets the SYSLOG_IDENTIFIER field. By default, it's the name of
+// the executable.
+func Identifier(identifier string) Option {
+	return func(l *journaldLogger) { l.identifier = identifier }
+}
+
+// LevelKey sets the key of the level field. By default, it's "level".
+func LevelKey(key string) Option {
+	return func(l *journaldLogger) { l.levelKey = key }
+}
+
+// MessageKey sets the key of the message field. By default, it's "msg".
+func MessageKey(key string) Option {
+	return func(l *journaldLogger) { l.messageKey = key }
+}
+
+func (l *journaldLogger) Log(keyvals ...interface{}) error {
+	var (
+		priority    = PriNotice
+		haveMessage bool
+		buf         bytes.Buffer
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		k, val := keyval.String(keyvals[i]), keyval.String(v)
+		switch {
+		case k == l.messageKey && !haveMessage:
+			writeField(&buf, "MESSAGE", val)
+			haveMessage = true
+			continue
+		case k == l.levelKey:
+			if level, err := levels.ParseLevel(val); err == nil {
+				priority = LevelPriority(level)
+			}
+		}
+		name := fieldName(k)
+		if reservedFields[name] {
+			name = "KEY_" + name
+		}
+		writeField(&buf, name, val)
+	}
+	writeField(&buf, "PRIORITY", strconv.Itoa(int(priority)))
+	if l.identifier != "" {
+		writeField(&buf, "SYSLOG_IDENTIFIER", l.identifier)
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	_, err := l.w.Write(buf.Bytes())
+	return err
+}
+
+// reservedFields are the fields written by the logger itself. Keys that map
+// onto them are renamed with a KEY_ prefix, so that entries don't carry the
+// same field twice.
+var reservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+}
+
+// writeField writes a field in the native protocol: values without newlines
+// as NAME=value, others as NAME, a newline, and the value prefixed with its
+// length as a little-endian 64-bit integer.
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if strings.IndexByte(value, '\n') < 0 {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+	} else {
+		buf.WriteByte('\n')
+		binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+}
+
+// fieldName returns a valid journal field name for key. Field names consist
+// of upper case letters, digits and underscores, and must not start with an
+// underscore, which is reserved for trusted fields, or a digit.
+func fieldName(key string) string {
+	b := []byte(strings.ToUpper(key))
+	for i, c := range b {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	name := strings.TrimLeft(string(b), "_0123456789")
+	if name == "" {
+		return "KEY"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
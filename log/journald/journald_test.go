@@ -0,0 +1,77 @@
+// +build !windows
+
+package journald_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/kit/log/journald"
+	"github.com/go-kit/kit/log/levels"
+)
+
+func TestJournaldLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A stand-in for the journal socket.
+	addr := filepath.Join(dir, "socket")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := levels.New(journald.NewLogger(conn, journald.Identifier("app")))
+	if err := logger.Warn().Log("msg", "two\nlines", "err", errors.New("boom"), "http.status", 503, "_trusted", true); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "LEVEL=warn\n" +
+		"MESSAGE\n\x09\x00\x00\x00\x00\x00\x00\x00two\nlines\n" +
+		"ERR=boom\n" +
+		"HTTP_STATUS=503\n" +
+		"TRUSTED=true\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=app\n"
+	if have := string(buf[:n]); want != have {
+		t.Errorf("\nwant %q\nhave %q", want, have)
+	}
+
+	// Keys colliding with the fields written by the logger are renamed.
+	if err := logger.Info().Log("msg", "hi", "priority", "high", "message", "dup", "msg", "again", "err", (*os.PathError)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err = server.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	want = "LEVEL=info\n" +
+		"MESSAGE=hi\n" +
+		"KEY_PRIORITY=high\n" +
+		"KEY_MESSAGE=dup\n" +
+		"MSG=again\n" +
+		"ERR=null\n" +
+		"PRIORITY=6\n" +
+		"SYSLOG_IDENTIFIER=app\n"
+	if have := string(buf[:n]); want != have {
+		t.Errorf("\nwant %q\nhave %q", want, have)
+	}
+}
@@ -0,0 +1,188 @@
+package levels
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Level identifies one of the five levels of a leveled logger, ordered from
+// least to most severe. Filtering operates on Levels, independently of the
+// values used to indicate them in log events.
+type Level int32
+
+// The levels of a leveled logger.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	CritLevel
+)
+
+const numLevels = int(CritLevel) + 1
+
+var levelNames = [numLevels]string{"debug", "info", "warn", "error", "crit"}
+
+// String returns the default value used to indicate the level.
+func (l Level) String() string {
+	if l < DebugLevel || l > CritLevel {
+		return fmt.Sprintf("Level(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level named s, which must be the default value used
+// to indicate it. "warning" and "critical" are accepted as well.
+func ParseLevel(s string) (Level, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "warning":
+		return WarnLevel, nil
+	case "critical":
+		return CritLevel, nil
+	}
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// Threshold is a minimum level that can be changed at runtime. It's safe for
+// concurrent use. Threshold implements http.Handler, so it can be mounted on
+// an admin endpoint: GET returns the current level as {"level":"info"}, and
+// PUT takes the same document to change it.
+type Threshold struct {
+	level int32
+}
+
+// NewThreshold returns a Threshold initially set to min.
+func NewThreshold(min Level) *Threshold {
+	return &Threshold{level: int32(min)}
+}
+
+// Level returns the current minimum level.
+func (t *Threshold) Level() Level {
+	return Level(atomic.LoadInt32(&t.level))
+}
+
+// SetLevel changes the minimum level.
+func (t *Threshold) SetLevel(min Level) {
+	atomic.StoreInt32(&t.level, int32(min))
+}
+
+// ServeHTTP implements http.Handler.
+func (t *Threshold) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	type document struct {
+		Level string `json:"level"`
+	}
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var doc document
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := ParseLevel(doc.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		t.SetLevel(level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(document{Level: t.Level().String()})
+}
+
+// filter decides which levels are enabled. Explicitly allowed or denied
+// levels take precedence over the threshold.
+type filter struct {
+	threshold *Threshold
+	override  [numLevels]int8 // 1 allows, -1 denies
+}
+
+func (f *filter) enabled(level Level) bool {
+	if level < DebugLevel || level > CritLevel {
+		return false
+	}
+	if f == nil {
+		return true
+	}
+	switch f.override[level] {
+	case 1:
+		return true
+	case -1:
+		return false
+	}
+	return f.threshold == nil || level >= f.threshold.Level()
+}
+
+// dynamic reports whether the level is enabled by the threshold, which may
+// change.
+func (f *filter) dynamic(level Level) bool {
+	return f != nil && f.threshold != nil &&
+		level >= DebugLevel && level <= CritLevel && f.override[level] == 0
+}
+
+// thresholdLogger drops log events while its level is below the threshold.
+type thresholdLogger struct {
+	next   log.Logger
+	filter *filter
+	level  Level
+}
+
+func (l *thresholdLogger) Log(keyvals ...interface{}) error {
+	if !l.filter.enabled(l.level) {
+		return nil
+	}
+	return l.next.Log(keyvals...)
+}
+
+// getFilter returns the filter of l, creating it if necessary. It's only
+// called by options, while the leveled logger is being constructed.
+func (l *Levels) getFilter() *filter {
+	if l.filter == nil {
+		l.filter = &filter{}
+	}
+	return l.filter
+}
+
+// MinLevel drops log events below the given level. By default, all levels
+// are enabled.
+func MinLevel(min Level) Option {
+	return DynamicThreshold(NewThreshold(min))
+}
+
+// DynamicThreshold drops log events below the current level of the
+// threshold, which may be changed at runtime.
+func DynamicThreshold(t *Threshold) Option {
+	return func(l *Levels) { l.getFilter().threshold = t }
+}
+
+// Allow enables the given levels, regardless of the threshold.
+func Allow(levels ...Level) Option {
+	return func(l *Levels) { l.getFilter().set(levels, 1) }
+}
+
+// Deny disables the given levels, regardless of the threshold.
+func Deny(levels ...Level) Option {
+	return func(l *Levels) { l.getFilter().set(levels, -1) }
+}
+
+func (f *filter) set(levels []Level, override int8) {
+	for _, level := range levels {
+		if level >= DebugLevel && level <= CritLevel {
+			f.override[level] = override
+		}
+	}
+}
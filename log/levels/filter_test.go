@@ -0,0 +1,122 @@
+package levels_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/levels"
+)
+
+func TestMinLevel(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := levels.New(log.NewLogfmtLogger(&buf), levels.MinLevel(levels.WarnLevel))
+
+	logger.Debug().Log("msg", "a")
+	logger.Info().Log("msg", "b")
+	logger.Warn().Log("msg", "c")
+	logger.With("k", "v").Error().Log("msg", "d")
+	if want, have := "level=warn msg=c\nlevel=error k=v msg=d\n", buf.String(); want != have {
+		t.Errorf("want %#v, have %#v", want, have)
+	}
+}
+
+func TestAllowDeny(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := levels.New(
+		log.NewLogfmtLogger(&buf),
+		levels.MinLevel(levels.ErrorLevel),
+		levels.Allow(levels.DebugLevel),
+		levels.Deny(levels.CritLevel),
+	)
+
+	for level, want := range map[levels.Level]bool{
+		levels.DebugLevel: true,
+		levels.InfoLevel:  false,
+		levels.WarnLevel:  false,
+		levels.ErrorLevel: true,
+		levels.CritLevel:  false,
+	} {
+		if have := logger.Enabled(level); want != have {
+			t.Errorf("%s: want %v, have %v", level, want, have)
+		}
+	}
+
+	logger.Debug().Log("msg", "a")
+	logger.Crit().Log("msg", "b")
+	if want, have := "level=debug msg=a\n", buf.String(); want != have {
+		t.Errorf("want %#v, have %#v", want, have)
+	}
+}
+
+func TestDynamicThreshold(t *testing.T) {
+	buf := bytes.Buffer{}
+	threshold := levels.NewThreshold(levels.InfoLevel)
+	logger := levels.New(log.NewLogfmtLogger(&buf), levels.DynamicThreshold(threshold))
+
+	logger.Debug().Log("msg", "a")
+	threshold.SetLevel(levels.DebugLevel)
+	logger.Debug().Log("msg", "b")
+	if want, have := "level=debug msg=b\n", buf.String(); want != have {
+		t.Errorf("want %#v, have %#v", want, have)
+	}
+}
+
+func TestDynamicThresholdStoredLogger(t *testing.T) {
+	buf := bytes.Buffer{}
+	threshold := levels.NewThreshold(levels.InfoLevel)
+	logger := levels.New(log.NewLogfmtLogger(&buf), levels.DynamicThreshold(threshold))
+	debug := logger.Debug()
+
+	debug.Log("msg", "a")
+	threshold.SetLevel(levels.DebugLevel)
+	debug.Log("msg", "b")
+	threshold.SetLevel(levels.WarnLevel)
+	debug.Log("msg", "c")
+	if want, have := "level=debug msg=b\n", buf.String(); want != have {
+		t.Errorf("want %#v, have %#v", want, have)
+	}
+}
+
+func TestThresholdHandler(t *testing.T) {
+	threshold := levels.NewThreshold(levels.InfoLevel)
+	for _, tc := range []struct {
+		method, body string
+		code         int
+		want         levels.Level
+	}{
+		{"GET", "", http.StatusOK, levels.InfoLevel},
+		{"PUT", `{"level":"warning"}`, http.StatusOK, levels.WarnLevel},
+		{"PUT", `{"level":"verbose"}`, http.StatusBadRequest, levels.WarnLevel},
+		{"DELETE", "", http.StatusMethodNotAllowed, levels.WarnLevel},
+	} {
+		r, _ := http.NewRequest(tc.method, "/loglevel", strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		threshold.ServeHTTP(w, r)
+		if want, have := tc.code, w.Code; want != have {
+			t.Errorf("%s %s: want %d, have %d", tc.method, tc.body, want, have)
+		}
+		if want, have := tc.want, threshold.Level(); want != have {
+			t.Errorf("%s %s: want %s, have %s", tc.method, tc.body, want, have)
+		}
+		if tc.code == http.StatusOK {
+			if want, have := `{"level":"`+tc.want.String()+`"}`+"\n", w.Body.String(); want != have {
+				t.Errorf("%s %s: want %q, have %q", tc.method, tc.body, want, have)
+			}
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []levels.Level{levels.DebugLevel, levels.InfoLevel, levels.WarnLevel, levels.ErrorLevel, levels.CritLevel} {
+		if have, err := levels.ParseLevel(strings.ToUpper(level.String())); err != nil || level != have {
+			t.Errorf("%s: want %s, have %s (%v)", level, level, have, err)
+		}
+	}
+	if _, err := levels.ParseLevel("verbose"); err == nil {
+		t.Error("want error, have none")
+	}
+}
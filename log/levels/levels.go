@@ -29,6 +29,8 @@ type Levels struct {
 	warnValue  string
 	errorValue string
 	critValue  string
+
+	filter *filter
 }
 
 // New creates a new leveled logger, wrapping the passed logger.
@@ -59,32 +61,54 @@ func (l Levels) With(keyvals ...interface{}) Levels {
 		warnValue:  l.warnValue,
 		errorValue: l.errorValue,
 		critValue:  l.critValue,
+		filter:     l.filter,
 	}
 }
 
+// Enabled reports whether log events of the given level are logged. Callers
+// can use it to skip building expensive keyvals.
+func (l Levels) Enabled(level Level) bool {
+	return l.filter.enabled(level)
+}
+
 // Debug returns a debug level logger.
 func (l Levels) Debug() log.Logger {
-	return l.ctx.WithPrefix(l.levelKey, l.debugValue)
+	return l.leveled(DebugLevel, l.debugValue)
 }
 
 // Info returns an info level logger.
 func (l Levels) Info() log.Logger {
-	return l.ctx.WithPrefix(l.levelKey, l.infoValue)
+	return l.leveled(InfoLevel, l.infoValue)
 }
 
 // Warn returns a warning level logger.
 func (l Levels) Warn() log.Logger {
-	return l.ctx.WithPrefix(l.levelKey, l.warnValue)
+	return l.leveled(WarnLevel, l.warnValue)
 }
 
 // Error returns an error level logger.
 func (l Levels) Error() log.Logger {
-	return l.ctx.WithPrefix(l.levelKey, l.errorValue)
+	return l.leveled(ErrorLevel, l.errorValue)
 }
 
 // Crit returns a critical level logger.
 func (l Levels) Crit() log.Logger {
-	return l.ctx.WithPrefix(l.levelKey, l.critValue)
+	return l.leveled(CritLevel, l.critValue)
+}
+
+// leveled returns a logger for the level. If the level depends on a
+// threshold, the logger checks it on every Log, so that changes to a
+// DynamicThreshold apply to loggers that were requested earlier. Otherwise,
+// a disabled level gets a nop logger.
+func (l Levels) leveled(level Level, value string) log.Logger {
+	logger := l.ctx.WithPrefix(l.levelKey, value)
+	if l.filter.dynamic(level) {
+		return &thresholdLogger{next: logger, filter: l.filter, level: level}
+	}
+	if !l.filter.enabled(level) {
+		return log.NewNopLogger()
+	}
+	return logger
 }
 
 // Option sets a parameter for leveled loggers.
@@ -0,0 +1,379 @@
+// Package rotate provides an io.Writer that writes to a file and rotates it,
+// for use with the Go kit loggers on hosts without a log shipper.
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrClosed is returned when writing to, or rotating, a closed Writer.
+var ErrClosed = errors.New("rotate: writer closed")
+
+// timeFormat is used in the names of rotated files. It sorts
+// chronologically.
+const timeFormat = "20060102T150405.000000000"
+
+// Writer is an io.Writer that appends to a file, and rotates it when it grows
+// too large or too old. Rotated files are renamed with a timestamp suffix,
+// optionally compressed, and pruned to keep a maximum number of them. Writer
+// is safe for concurrent use.
+//
+//	w, err := rotate.NewWriter("/var/log/app.log", rotate.MaxSize(100<<20), rotate.MaxFiles(10))
+//	logger := log.NewLogfmtLogger(w)
+type Writer struct {
+	path     string
+	maxSize  int64
+	interval time.Duration
+	maxFiles int
+	compress bool
+	sighup   bool
+	now      func() time.Time
+
+	mtx    sync.Mutex
+	file   *os.File // nil after a failed rotation or reopen, until the next write
+	size   int64
+	opened time.Time
+	closed bool
+
+	cleanup sync.Mutex     // serializes compression and pruning
+	wg      sync.WaitGroup // tracks background cleanups
+	signalc chan os.Signal
+	quitc   chan struct{}
+}
+
+// NewWriter opens, or creates, the file at path for appending, and returns a
+// Writer for it.
+func NewWriter(path string, options ...Option) (*Writer, error) {
+	w := &Writer{
+		path: path,
+		now:  time.Now,
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	if w.sighup {
+		w.signalc = make(chan os.Signal, 1)
+		w.quitc = make(chan struct{})
+		signal.Notify(w.signalc, syscall.SIGHUP)
+		go w.handleSignals()
+	}
+	return w, nil
+}
+
+// Option sets a parameter for the Writer.
+type Option func(*Writer)
+
+// MaxSize rotates the file before a write would make it larger than n bytes.
+// By default, files are not rotated by size.
+func MaxSize(n int64) Option {
+	return func(w *Writer) { w.maxSize = n }
+}
+
+// Interval rotates the file once it has been written to for d. By default,
+// files are not rotated by age.
+func Interval(d time.Duration) Option {
+	return func(w *Writer) { w.interval = d }
+}
+
+// MaxFiles keeps at most n rotated files, removing the oldest ones. By
+// default, all rotated files are kept.
+func MaxFiles(n int) Option {
+	return func(w *Writer) { w.maxFiles = n }
+}
+
+// Compress gzips rotated files in the background. By default, rotated files
+// are left uncompressed.
+func Compress(compress bool) Option {
+	return func(w *Writer) { w.compress = compress }
+}
+
+// ReopenOnSIGHUP reopens the file whenever the process receives SIGHUP, so
+// that an external tool such as logrotate can move it away. By default,
+// signals are ignored.
+func ReopenOnSIGHUP(reopen bool) Option {
+	return func(w *Writer) { w.sighup = reopen }
+}
+
+// Clock sets the function used to tell the current time, for time based
+// rotation and the names of rotated files. By default, it's time.Now.
+func Clock(now func() time.Time) Option {
+	return func(w *Writer) { w.now = now }
+}
+
+// Write implements io.Writer. It rotates the file first, if needed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (w *Writer) Rotate() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	return w.rotate()
+}
+
+// Reopen closes the file and opens the file at the same path again, which
+// may be a new file if the old one has been moved away. If opening fails, the
+// next write tries again.
+func (w *Writer) Reopen() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the file, stops handling signals, and waits for background
+// compression to finish.
+func (w *Writer) Close() error {
+	w.mtx.Lock()
+	var err error
+	if !w.closed {
+		w.closed = true
+		err = w.closeFile()
+		if w.quitc != nil {
+			signal.Stop(w.signalc)
+			close(w.quitc)
+		}
+	}
+	w.mtx.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+func (w *Writer) shouldRotate(n int64) bool {
+	if w.maxSize > 0 && w.size+n > w.maxSize {
+		return true
+	}
+	if w.interval > 0 && w.now().Sub(w.opened) >= w.interval {
+		return true
+	}
+	return false
+}
+
+// open opens the file at w.path. It must be called with w.mtx held.
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size, w.opened = f, fi.Size(), w.now()
+	return nil
+}
+
+// closeFile closes the file, if it's open. It must be called with w.mtx held.
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate moves the file away, opens a new one, and starts the cleanup of
+// rotated files. If the file can't be moved, it's reopened, so that later
+// writes can try again. It must be called with w.mtx held.
+func (w *Writer) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	rotated := uniqueName(w.path + "." + w.now().Format(timeFormat))
+	if err := os.Rename(w.path, rotated); err != nil {
+		w.open() // if this fails too, the next write tries again
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.cleanupRotated(rotated)
+	}()
+	return nil
+}
+
+// cleanupRotated compresses the newly rotated file, if required, and removes
+// the oldest rotated files beyond the maximum. Errors are ignored, as there's
+// nowhere to report them; the rotated files are left in place.
+func (w *Writer) cleanupRotated(rotated string) {
+	w.cleanup.Lock()
+	defer w.cleanup.Unlock()
+
+	if w.compress {
+		if err := compressFile(rotated); err == nil {
+			os.Remove(rotated)
+		}
+	}
+	if w.maxFiles <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil {
+		return
+	}
+	var suffixes []string
+	for _, m := range matches {
+		if suffix := strings.TrimPrefix(m, w.path+"."); isRotated(suffix) {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	sort.Sort(byRotation(suffixes))
+	for len(suffixes) > w.maxFiles {
+		os.Remove(w.path + "." + suffixes[0])
+		suffixes = suffixes[1:]
+	}
+}
+
+// uniqueName returns name, or name with the first numeric suffix (".1", ".2",
+// ...) that isn't taken, so that a clock returning the same time twice doesn't
+// make a rotation overwrite an earlier one. A name is taken if it exists
+// either as is or compressed.
+func uniqueName(name string) string {
+	candidate := name
+	for n := 1; exists(candidate) || exists(candidate+".gz"); n++ {
+		candidate = name + "." + strconv.Itoa(n)
+	}
+	return candidate
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// isRotated reports whether suffix is that of a rotated file, i.e. a
+// timestamp, optionally followed by a numeric suffix and ".gz". Other files
+// next to the log file are left alone.
+func isRotated(suffix string) bool {
+	_, _, ok := parseRotated(suffix)
+	return ok
+}
+
+// parseRotated returns the timestamp and numeric suffix of a rotated file's
+// suffix.
+func parseRotated(suffix string) (t time.Time, n int, ok bool) {
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	if len(suffix) < len(timeFormat) {
+		return t, 0, false
+	}
+	t, err := time.Parse(timeFormat, suffix[:len(timeFormat)])
+	if err != nil {
+		return t, 0, false
+	}
+	if rest := suffix[len(timeFormat):]; rest != "" {
+		if !strings.HasPrefix(rest, ".") {
+			return t, 0, false
+		}
+		if n, err = strconv.Atoi(rest[1:]); err != nil || n < 1 {
+			return t, 0, false
+		}
+	}
+	return t, n, true
+}
+
+// byRotation sorts the suffixes of rotated files from oldest to newest. They
+// don't sort as strings once numeric suffixes reach ".10", or when only some
+// of them are compressed.
+type byRotation []string
+
+func (a byRotation) Len() int      { return len(a) }
+func (a byRotation) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byRotation) Less(i, j int) bool {
+	ti, ni, _ := parseRotated(a[i])
+	tj, nj, _ := parseRotated(a[j])
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return ni < nj
+}
+
+func (w *Writer) handleSignals() {
+	for {
+		select {
+		case <-w.signalc:
+			w.Reopen()
+		case <-w.quitc:
+			return
+		}
+	}
+}
+
+// compressFile writes a gzipped copy of the file at path to path + ".gz".
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path+".gz")
+}
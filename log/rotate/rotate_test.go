@@ -0,0 +1,271 @@
+package rotate_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log/rotate"
+)
+
+func TestSizeRotation(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	w, err := rotate.NewWriter(path, rotate.MaxSize(10), rotate.MaxFiles(2), rotate.Clock(tick()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each write exceeds the limit with the previous one, so each rotates.
+	// Only the two most recent rotated files are kept.
+	if want, have := []string{"bbbbbb\n", "cccccc\n"}, readRotated(t, path); !equal(want, have) {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "dddddd\n", readFile(t, path); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if _, err := w.Write([]byte("x")); err != rotate.ErrClosed {
+		t.Errorf("want %v, have %v", rotate.ErrClosed, err)
+	}
+}
+
+func TestIntervalRotationCompressed(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	path := filepath.Join(dir, "app.log")
+	w, err := rotate.NewWriter(path, rotate.Interval(time.Hour), rotate.Compress(true), rotate.Clock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("first\n"))
+	now = now.Add(59 * time.Minute)
+	w.Write([]byte("second\n"))
+	now = now.Add(time.Minute)
+	w.Write([]byte("third\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, _ := filepath.Glob(path + ".*")
+	if want, have := 1, len(matches); want != have {
+		t.Fatalf("want %d rotated file, have %v", want, matches)
+	}
+	if want, have := ".gz", filepath.Ext(matches[0]); want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "first\nsecond\n", string(buf); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "third\n", readFile(t, path); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	w, err := rotate.NewWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".1"); err != nil { // like logrotate
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+
+	if want, have := "before\n", readFile(t, path+".1"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "after\n", readFile(t, path); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestRotateFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// The log file's name is as long as allowed, so the timestamp suffix
+	// makes the name of the rotated file too long and the rename fails.
+	path := filepath.Join(dir, strings.Repeat("a", 251)+".log")
+	w, err := rotate.NewWriter(path, rotate.Clock(tick()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("before\n"))
+
+	if err := w.Rotate(); err == nil {
+		t.Fatal("want error, have none")
+	}
+
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "before\nafter\n", readFile(t, path); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestPruneKeepsOtherFiles(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	for _, name := range []string{path + ".lock", path + ".bak"} {
+		if err := ioutil.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := rotate.NewWriter(path, rotate.MaxFiles(1), rotate.Clock(tick()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("x\n"))
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, _ := filepath.Glob(path + ".*")
+	if want, have := 3, len(matches); want != have {
+		t.Errorf("want %d files, have %v", want, matches)
+	}
+	for _, name := range []string{path + ".lock", path + ".bak"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFrozenClockKeepsRotatedFiles(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	path := filepath.Join(dir, "app.log")
+	w, err := rotate.NewWriter(path, rotate.MaxFiles(2), rotate.Clock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		w.Write([]byte(strconv.Itoa(i) + "\n"))
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every rotation gets the same timestamp, so each one after the first
+	// takes the next numeric suffix, and pruning keeps the two newest.
+	rotated := path + "." + now.Format("20060102T150405.000000000")
+	for name, want := range map[string]string{rotated + ".10": "10\n", rotated + ".11": "11\n"} {
+		if have := readFile(t, name); want != have {
+			t.Errorf("%s: want %q, have %q", name, want, have)
+		}
+	}
+	matches, _ := filepath.Glob(path + ".*")
+	if want, have := 2, len(matches); want != have {
+		t.Errorf("want %d files, have %v", want, matches)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// tick returns a clock that advances by a second on every call, so that
+// rotated files get distinct names.
+func tick() func() time.Time {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
+
+func readRotated(t *testing.T, path string) []string {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(matches)
+	var contents []string
+	for _, m := range matches {
+		contents = append(contents, readFile(t, m))
+	}
+	return contents
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
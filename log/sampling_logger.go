@@ -0,0 +1,178 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingLogger limits the volume of repetitive log events. Events are
+// grouped by the values of a set of keys, "msg" by default. Within each
+// interval, the first events of a group are logged, and after that only every
+// Mth. The number of suppressed events of a group is logged once its interval
+// has elapsed, under the "suppressed" key, together with the group keyvals.
+//
+// Suppressed counts are reported by calls to Log, so that the SamplingLogger
+// doesn't need a goroutine of its own; groups that go quiet are reported by
+// the next Log call of any group once their interval has elapsed. Counts that
+// are still pending when all traffic stops are reported by Flush, which
+// applications should call before exiting, and may call periodically, e.g.
+// from a time.Ticker loop, to report counts without waiting for more events.
+type SamplingLogger struct {
+	next       Logger
+	first      int
+	thereafter int
+	interval   time.Duration
+	keys       []string
+	now        func() time.Time
+
+	mtx       sync.Mutex
+	groups    map[string]*sampleGroup
+	nextSweep time.Time
+}
+
+type sampleGroup struct {
+	keyvals    []interface{}
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// NewSamplingLogger returns a new SamplingLogger that logs the first events
+// of each group per interval to next, and after that every thereafter-th. If
+// thereafter is zero, all further events of the interval are suppressed.
+func NewSamplingLogger(next Logger, first, thereafter int, interval time.Duration, options ...SamplingLoggerOption) *SamplingLogger {
+	l := &SamplingLogger{
+		next:       next,
+		first:      first,
+		thereafter: thereafter,
+		interval:   interval,
+		keys:       []string{"msg"},
+		now:        time.Now,
+		groups:     map[string]*sampleGroup{},
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// SamplingLoggerOption sets a parameter for the SamplingLogger.
+type SamplingLoggerOption func(*SamplingLogger)
+
+// SampleKeys sets the keys whose values group log events. Events lacking all
+// of the keys form a group of their own. By default, events are grouped by
+// the value of "msg".
+func SampleKeys(keys ...string) SamplingLoggerOption {
+	return func(l *SamplingLogger) { l.keys = keys }
+}
+
+// SampleClock sets the function used to tell the current time. By default,
+// it's time.Now.
+func SampleClock(now func() time.Time) SamplingLoggerOption {
+	return func(l *SamplingLogger) { l.now = now }
+}
+
+// Log implements Logger. Suppressed events return a nil error.
+func (l *SamplingLogger) Log(keyvals ...interface{}) error {
+	id, groupKeyvals := l.group(keyvals)
+	now := l.now()
+
+	l.mtx.Lock()
+	reports := l.sweep(now)
+	g, ok := l.groups[id]
+	if !ok {
+		g = &sampleGroup{keyvals: groupKeyvals, start: now}
+		l.groups[id] = g
+	} else if now.Sub(g.start) >= l.interval {
+		if g.suppressed > 0 {
+			reports = append(reports, g.report())
+		}
+		g.start, g.count, g.suppressed = now, 0, 0
+	}
+	g.count++
+	sampled := g.count <= l.first || (l.thereafter > 0 && (g.count-l.first)%l.thereafter == 0)
+	if !sampled {
+		g.suppressed++
+	}
+	l.mtx.Unlock()
+
+	for _, report := range reports {
+		l.next.Log(report...)
+	}
+	if !sampled {
+		return nil
+	}
+	return l.next.Log(keyvals...)
+}
+
+// Flush logs the suppressed counts of all groups that have any, and resets
+// them. Sampling within the current intervals is unaffected. It returns the
+// first error of the next logger.
+func (l *SamplingLogger) Flush() error {
+	l.mtx.Lock()
+	var reports [][]interface{}
+	for _, g := range l.groups {
+		if g.suppressed > 0 {
+			reports = append(reports, g.report())
+			g.suppressed = 0
+		}
+	}
+	l.mtx.Unlock()
+
+	var err error
+	for _, report := range reports {
+		if e := l.next.Log(report...); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// sweep removes the groups whose interval has elapsed, at most once per
+// interval, and returns the reports of those with suppressed events.
+func (l *SamplingLogger) sweep(now time.Time) [][]interface{} {
+	if now.Before(l.nextSweep) {
+		return nil
+	}
+	l.nextSweep = now.Add(l.interval)
+	var reports [][]interface{}
+	for id, g := range l.groups {
+		if now.Sub(g.start) < l.interval {
+			continue
+		}
+		if g.suppressed > 0 {
+			reports = append(reports, g.report())
+		}
+		delete(l.groups, id)
+	}
+	return reports
+}
+
+// group returns the identity of the group of keyvals, and the keyvals that
+// describe the group. Values are quoted in the identity, so that a missing
+// key, which leaves its part empty, differs from an empty value.
+func (l *SamplingLogger) group(keyvals []interface{}) (string, []interface{}) {
+	var (
+		parts        = make([]string, len(l.keys))
+		groupKeyvals []interface{}
+	)
+	for i, key := range l.keys {
+		for j := 0; j < len(keyvals)-1; j += 2 {
+			if k, ok := keyvals[j].(string); ok && k == key {
+				parts[i] = strconv.Quote(fmt.Sprint(keyvals[j+1]))
+				groupKeyvals = append(groupKeyvals, key, keyvals[j+1])
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "\x00"), groupKeyvals
+}
+
+func (g *sampleGroup) report() []interface{} {
+	kvs := make([]interface{}, 0, len(g.keyvals)+2)
+	kvs = append(kvs, g.keyvals...)
+	return append(kvs, "suppressed", g.suppressed)
+}
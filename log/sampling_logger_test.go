@@ -0,0 +1,101 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestSamplingLogger(t *testing.T) {
+	var (
+		buf   = &bytes.Buffer{}
+		now   = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	)
+	logger := log.NewSamplingLogger(log.NewLogfmtLogger(buf), 2, 3, time.Second, log.SampleClock(clock))
+
+	for i := 1; i <= 8; i++ {
+		logger.Log("msg", "boom", "n", i)
+	}
+	logger.Log("msg", "other", "n", 1)
+	if want, have := "msg=boom n=1\nmsg=boom n=2\nmsg=boom n=5\nmsg=boom n=8\nmsg=other n=1\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	logger.Log("msg", "boom", "n", 9)
+	if want, have := "msg=boom suppressed=4\nmsg=boom n=9\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestSamplingLoggerQuietGroup(t *testing.T) {
+	var (
+		buf   = &bytes.Buffer{}
+		now   = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	)
+	logger := log.NewSamplingLogger(
+		log.NewLogfmtLogger(buf), 1, 0, time.Minute,
+		log.SampleKeys("msg", "err"),
+		log.SampleClock(clock),
+	)
+
+	logger.Log("msg", "query failed", "err", "timeout", "id", 1)
+	logger.Log("msg", "query failed", "err", "timeout", "id", 2)
+	logger.Log("msg", "query failed", "err", "refused", "id", 3)
+	if want, have := "msg=\"query failed\" err=timeout id=1\nmsg=\"query failed\" err=refused id=3\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// The timeout group went quiet; it's reported by another group.
+	buf.Reset()
+	now = now.Add(time.Minute)
+	logger.Log("msg", "hello")
+	if want, have := "msg=\"query failed\" err=timeout suppressed=1\nmsg=hello\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestSamplingLoggerFlush(t *testing.T) {
+	var (
+		buf   = &bytes.Buffer{}
+		now   = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	)
+	logger := log.NewSamplingLogger(log.NewLogfmtLogger(buf), 1, 0, time.Minute, log.SampleClock(clock))
+
+	logger.Log("msg", "boom", "n", 1)
+	logger.Log("msg", "boom", "n", 2)
+	logger.Log("msg", "boom", "n", 3)
+	logger.Log("msg", "quiet", "n", 1)
+	buf.Reset()
+	if err := logger.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "msg=boom suppressed=2\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// Counts are reported once, and sampling continues within the interval.
+	buf.Reset()
+	logger.Flush()
+	logger.Log("msg", "boom", "n", 4)
+	if want, have := "", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestSamplingLoggerMissingKey(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := log.NewSamplingLogger(log.NewLogfmtLogger(buf), 1, 0, time.Minute)
+
+	logger.Log("msg", "", "n", 1)
+	logger.Log("n", 2)
+	if want, have := "msg= n=1\nn=2\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
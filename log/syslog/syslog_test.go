@@ -0,0 +1,96 @@
+// +build !windows
+
+package syslog_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log/levels"
+	"github.com/go-kit/kit/log/syslog"
+)
+
+func TestSyslogLogger(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A stand-in for the syslog daemon.
+	addr := filepath.Join(dir, "log")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.Dial("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	now := time.Date(2016, 1, 2, 3, 4, 5, 6000, time.UTC)
+	options := []syslog.Option{
+		syslog.Hostname("host"),
+		syslog.AppName("app"),
+		syslog.Clock(func() time.Time { return now }),
+	}
+	plain := syslog.NewLogger(conn, options...)
+	logger := levels.New(syslog.NewLogger(conn, append(options, syslog.WithFacility(syslog.Local0))...))
+
+	for _, tc := range []struct {
+		log  func() error
+		want string
+	}{
+		{
+			func() error { return logger.Error().Log("msg", "boom", "err", errors.New(`bad "quote"]`)) },
+			`<131>1 2016-01-02T03:04:05.000006Z host app %d - [kit@32473 level="error" err="bad \"quote\"\]"] boom`,
+		},
+		{
+			func() error { return logger.Debug().Log("a key", 1) },
+			`<135>1 2016-01-02T03:04:05.000006Z host app %d - [kit@32473 level="debug" a_key="1"]`,
+		},
+		{
+			func() error { return plain.Log("msg", "hi") },
+			`<13>1 2016-01-02T03:04:05.000006Z host app %d - - hi`,
+		},
+		{
+			func() error { return plain.Log("err", (*os.PathError)(nil)) },
+			`<13>1 2016-01-02T03:04:05.000006Z host app %d - [kit@32473 err="null"]`,
+		},
+	} {
+		if err := tc.log(); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 1024)
+		n, err := server.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := fmt.Sprintf(tc.want, os.Getpid()), string(buf[:n]); want != have {
+			t.Errorf("\nwant %s\nhave %s", want, have)
+		}
+	}
+}
+
+func TestLevelSeverity(t *testing.T) {
+	for level, want := range map[levels.Level]syslog.Severity{
+		levels.DebugLevel: syslog.Debug,
+		levels.InfoLevel:  syslog.Info,
+		levels.WarnLevel:  syslog.Warning,
+		levels.ErrorLevel: syslog.Err,
+		levels.CritLevel:  syslog.Crit,
+	} {
+		if have := syslog.LevelSeverity(level); want != have {
+			t.Errorf("%s: want %d, have %d", level, want, have)
+		}
+	}
+}
@@ -11,20 +11,37 @@ import (
 // TraceServer returns a Middleware that wraps the `next` Endpoint in an
 // OpenTracing Span called `operationName`.
 //
-// If `ctx` already has a Span, it is re-used and the operation name is
-// overwritten. If `ctx` does not yet have a Span, one is created here.
+// If `ctx` already has a Span, e.g. one created by FromHTTPRequest or
+// FromGRPCRequest, it is re-used and the operation name is overwritten. If
+// `ctx` does not yet have a Span, one is created here. Either way, the Span is
+// finished when `next` returns, and tagged if `next` returns an error.
+//
+// A Span created by StartHTTPServerSpan is finished by FinishHTTPServerSpan
+// instead, so TraceServer starts a child Span of it for the endpoint.
 func TraceServer(tracer opentracing.Tracer, operationName string) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
-		return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 			serverSpan := opentracing.SpanFromContext(ctx)
-			if serverSpan == nil {
+			switch {
+			case serverSpan == nil:
 				// All we can do is create a new root span.
 				serverSpan = tracer.StartSpan(operationName)
-			} else {
+				otext.SpanKind.Set(serverSpan, otext.SpanKindRPCServer)
+			case serverSpan == spanFromContext(ctx, httpServerSpanKey):
+				serverSpan = tracer.StartSpanWithOptions(opentracing.StartSpanOptions{
+					OperationName: operationName,
+					Parent:        serverSpan,
+				})
+			default:
 				serverSpan.SetOperationName(operationName)
+				otext.SpanKind.Set(serverSpan, otext.SpanKindRPCServer)
 			}
-			defer serverSpan.Finish()
-			otext.SpanKind.Set(serverSpan, otext.SpanKindRPCServer)
+			defer func() {
+				if err != nil {
+					setError(serverSpan, err)
+				}
+				serverSpan.Finish()
+			}()
 			ctx = opentracing.ContextWithSpan(ctx, serverSpan)
 			return next(ctx, request)
 		}
@@ -33,18 +50,54 @@ func TraceServer(tracer opentracing.Tracer, operationName string) endpoint.Middl
 
 // TraceClient returns a Middleware that wraps the `next` Endpoint in an
 // OpenTracing Span called `operationName`.
+//
+// The Span is a child of the Span in `ctx`, if any, and is incorporated in the
+// Context passed to `next`, so that ToHTTPRequest and ToGRPCRequest propagate
+// it. It is finished when `next` returns, and tagged if `next` returns an
+// error.
 func TraceClient(tracer opentracing.Tracer, operationName string) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
-		return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 			parentSpan := opentracing.SpanFromContext(ctx)
 			clientSpan := tracer.StartSpanWithOptions(opentracing.StartSpanOptions{
 				OperationName: operationName,
 				Parent:        parentSpan, // may be nil
 			})
-			defer clientSpan.Finish()
+			defer func() {
+				if err != nil {
+					setError(clientSpan, err)
+				}
+				clientSpan.Finish()
+			}()
 			otext.SpanKind.Set(clientSpan, otext.SpanKindRPCClient)
 			ctx = opentracing.ContextWithSpan(ctx, clientSpan)
+			ctx = context.WithValue(ctx, clientSpanKey, clientSpan)
 			return next(ctx, request)
 		}
 	}
 }
+
+type contextKey int
+
+const (
+	// clientSpanKey holds the Span created by TraceClient, which the
+	// transport RequestFuncs and ResponseFuncs may tag.
+	clientSpanKey contextKey = iota
+
+	// httpServerSpanKey holds the Span created by StartHTTPServerSpan, which
+	// FinishHTTPServerSpan finishes.
+	httpServerSpanKey
+)
+
+// spanFromContext returns the Span stored in ctx under key, or nil.
+func spanFromContext(ctx context.Context, key contextKey) opentracing.Span {
+	span, _ := ctx.Value(key).(opentracing.Span)
+	return span
+}
+
+// setError marks the Span as failed, per the OpenTracing semantic
+// conventions, and records the error itself as a log event.
+func setError(span opentracing.Span, err error) {
+	span.SetTag("error", true)
+	span.LogEventWithPayload("error", err)
+}
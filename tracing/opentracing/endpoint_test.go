@@ -1,6 +1,7 @@
 package opentracing_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/opentracing/opentracing-go"
@@ -94,3 +95,55 @@ func TestTraceClient(t *testing.T) {
 		t.Errorf("Want ParentID %q, have %q", want, have)
 	}
 }
+
+func TestTraceServerError(t *testing.T) {
+	tracer := mocktracer.New()
+
+	var innerEndpoint endpoint.Endpoint
+	innerEndpoint = func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	}
+	tracedEndpoint := kitot.TraceServer(tracer, "testOp")(innerEndpoint)
+	if _, err := tracedEndpoint(context.Background(), struct{}{}); err == nil {
+		t.Fatal("want error, have none")
+	}
+	if want, have := 1, len(tracer.FinishedSpans); want != have {
+		t.Fatalf("Want %v span(s), found %v", want, have)
+	}
+
+	endpointSpan := tracer.FinishedSpans[0]
+	if want, have := true, endpointSpan.Tag("error"); want != have {
+		t.Errorf("Want error tag %v, have %v", want, have)
+	}
+	if want, have := 1, len(endpointSpan.Logs()); want != have {
+		t.Errorf("Want %v log(s), have %v", want, have)
+	}
+}
+
+func TestTraceClientError(t *testing.T) {
+	tracer := mocktracer.New()
+
+	var innerEndpoint endpoint.Endpoint
+	innerEndpoint = func(context.Context, interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	}
+	tracedEndpoint := kitot.TraceClient(tracer, "testOp")(innerEndpoint)
+	if _, err := tracedEndpoint(context.Background(), struct{}{}); err == nil {
+		t.Fatal("want error, have none")
+	}
+	if want, have := 1, len(tracer.FinishedSpans); want != have {
+		t.Fatalf("Want %v span(s), found %v", want, have)
+	}
+
+	endpointSpan := tracer.FinishedSpans[0]
+	if want, have := true, endpointSpan.Tag("error"); want != have {
+		t.Errorf("Want error tag %v, have %v", want, have)
+	}
+	logs := endpointSpan.Logs()
+	if want, have := 1, len(logs); want != have {
+		t.Fatalf("Want %v log(s), have %v", want, have)
+	}
+	if want, have := "boom", logs[0].Payload.(error).Error(); want != have {
+		t.Errorf("Want %q, have %q", want, have)
+	}
+}
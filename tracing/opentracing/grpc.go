@@ -32,7 +32,8 @@ func ToGRPCRequest(tracer opentracing.Tracer, logger log.Logger) func(ctx contex
 // OpenTracing trace found in `req` and starts a new Span called
 // `operationName` accordingly. If no trace could be found in `req`, the Span
 // will be a trace root. The Span is incorporated in the returned Context and
-// can be retrieved with opentracing.SpanFromContext(ctx).
+// can be retrieved with opentracing.SpanFromContext(ctx). Wrap the endpoint
+// with TraceServer, which finishes the Span.
 //
 // The logger is used to report errors and may be nil.
 func FromGRPCRequest(tracer opentracing.Tracer, operationName string, logger log.Logger) func(ctx context.Context, md *metadata.MD) context.Context {
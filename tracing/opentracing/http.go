@@ -4,6 +4,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -15,27 +16,28 @@ import (
 
 // ToHTTPRequest returns an http RequestFunc that injects an OpenTracing Span
 // found in `ctx` into the http headers. If no such Span can be found, the
-// RequestFunc is a noop.
+// RequestFunc is a noop. If the Span was created by TraceClient, it's also
+// tagged with the method and URL of the request, and the address of the
+// peer; other Spans, like the Span of a server making the request, are left
+// untagged.
 //
 // The logger is used to report errors and may be nil.
 func ToHTTPRequest(tracer opentracing.Tracer, logger log.Logger) kithttp.RequestFunc {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
 	return func(ctx context.Context, req *http.Request) context.Context {
 		// Try to find a Span in the Context.
 		if span := opentracing.SpanFromContext(ctx); span != nil {
-			// Add standard OpenTracing tags.
-			ext.HTTPMethod.Set(span, req.URL.RequestURI())
-			host, portString, err := net.SplitHostPort(req.URL.Host)
-			if err == nil {
-				ext.PeerHostname.Set(span, host)
-				if port, err := strconv.Atoi(portString); err != nil {
-					ext.PeerPort.Set(span, uint16(port))
-				}
-			} else {
-				ext.PeerHostname.Set(span, req.URL.Host)
+			// Add standard OpenTracing tags to client Spans.
+			if span == spanFromContext(ctx, clientSpanKey) {
+				ext.HTTPMethod.Set(span, req.Method)
+				ext.HTTPUrl.Set(span, req.URL.String())
+				setPeer(span, req.URL.Host)
 			}
 
 			// There's nothing we can do with any errors here.
-			if err = tracer.Inject(
+			if err := tracer.Inject(
 				span,
 				opentracing.TextMap,
 				opentracing.HTTPHeaderTextMapCarrier(req.Header),
@@ -51,10 +53,14 @@ func ToHTTPRequest(tracer opentracing.Tracer, logger log.Logger) kithttp.Request
 // OpenTracing trace found in `req` and starts a new Span called
 // `operationName` accordingly. If no trace could be found in `req`, the Span
 // will be a trace root. The Span is incorporated in the returned Context and
-// can be retrieved with opentracing.SpanFromContext(ctx).
+// can be retrieved with opentracing.SpanFromContext(ctx). Wrap the endpoint
+// with TraceServer to finish the Span.
 //
 // The logger is used to report errors and may be nil.
 func FromHTTPRequest(tracer opentracing.Tracer, operationName string, logger log.Logger) kithttp.RequestFunc {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
 	return func(ctx context.Context, req *http.Request) context.Context {
 		// Try to join to a trace propagated in `req`.
 		span, err := tracer.Join(
@@ -68,6 +74,85 @@ func FromHTTPRequest(tracer opentracing.Tracer, operationName string, logger log
 				logger.Log("err", err)
 			}
 		}
+		ext.SpanKind.Set(span, ext.SpanKindRPCServer)
+		ext.HTTPMethod.Set(span, req.Method)
+		ext.HTTPUrl.Set(span, requestURL(req))
+		setPeer(span, req.RemoteAddr)
 		return opentracing.ContextWithSpan(ctx, span)
 	}
 }
+
+// StartHTTPServerSpan is like FromHTTPRequest, but the Span is left open until
+// FinishHTTPServerSpan, used as the server finalizer, finishes it, so that it
+// can record the status code of the response. Endpoints wrapped with
+// TraceServer get a child Span of their own.
+//
+// The logger is used to report errors and may be nil.
+func StartHTTPServerSpan(tracer opentracing.Tracer, operationName string, logger log.Logger) kithttp.RequestFunc {
+	from := FromHTTPRequest(tracer, operationName, logger)
+	return func(ctx context.Context, req *http.Request) context.Context {
+		ctx = from(ctx, req)
+		return context.WithValue(ctx, httpServerSpanKey, opentracing.SpanFromContext(ctx))
+	}
+}
+
+// FromHTTPResponse returns an http ClientResponseFunc that tags the
+// OpenTracing Span created by TraceClient with the status code of the
+// response. If no such Span can be found, the ClientResponseFunc is a noop.
+// Use it in clients, together with ToHTTPRequest and TraceClient.
+func FromHTTPResponse() kithttp.ClientResponseFunc {
+	return func(ctx context.Context, resp *http.Response) context.Context {
+		if span := spanFromContext(ctx, clientSpanKey); span != nil {
+			ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+		}
+		return ctx
+	}
+}
+
+// FinishHTTPServerSpan returns an http ServerFinalizerFunc that tags the
+// OpenTracing Span created by StartHTTPServerSpan with the status code of the
+// response, marks it as failed if the request failed, and finishes it. If no
+// such Span can be found, the ServerFinalizerFunc is a noop.
+func FinishHTTPServerSpan() kithttp.ServerFinalizerFunc {
+	return func(ctx context.Context, code int, _ int64, _ time.Duration, domain string) {
+		span := spanFromContext(ctx, httpServerSpanKey)
+		if span == nil {
+			return
+		}
+		ext.HTTPStatusCode.Set(span, uint16(code))
+		if domain != "" || code >= http.StatusInternalServerError {
+			span.SetTag("error", true)
+		}
+		span.Finish()
+	}
+}
+
+// requestURL returns the full URL of a server request, whose URL usually
+// holds only the path and query.
+func requestURL(req *http.Request) string {
+	u := *req.URL
+	if u.Host == "" {
+		u.Host = req.Host
+	}
+	if u.Host != "" && u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return u.String()
+}
+
+// setPeer tags the Span with the host and port of the remote peer. The
+// address may lack a port, in which case only the host is recorded.
+func setPeer(span opentracing.Span, hostport string) {
+	host, portString, err := net.SplitHostPort(hostport)
+	if err != nil {
+		ext.PeerHostname.Set(span, hostport)
+		return
+	}
+	ext.PeerHostname.Set(span, host)
+	if port, err := strconv.ParseUint(portString, 10, 16); err == nil {
+		ext.PeerPort.Set(span, uint16(port))
+	}
+}
@@ -3,8 +3,10 @@ package opentracing_test
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"golang.org/x/net/context"
 
@@ -57,3 +59,121 @@ func TestTraceHTTPRequestRoundtrip(t *testing.T) {
 		t.Errorf("Want %q, have %q", want, have)
 	}
 }
+
+func TestToHTTPRequestTags(t *testing.T) {
+	tracer := mocktracer.New()
+	req, _ := http.NewRequest("POST", "http://test.biz:8080/url?q=1", nil)
+	client := kitot.TraceClient(tracer, "client")(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		ctx = kitot.ToHTTPRequest(tracer, nil)(ctx, req)
+		kitot.FromHTTPResponse()(ctx, &http.Response{StatusCode: http.StatusTeapot})
+		return nil, nil
+	})
+	if _, err := client(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(tracer.FinishedSpans); want != have {
+		t.Fatalf("Want %v span(s), found %v", want, have)
+	}
+	span := tracer.FinishedSpans[0]
+
+	for key, want := range map[string]interface{}{
+		string(ext.SpanKind):       ext.SpanKindRPCClient,
+		string(ext.HTTPMethod):     "POST",
+		string(ext.HTTPUrl):        "http://test.biz:8080/url?q=1",
+		string(ext.PeerHostname):   "test.biz",
+		string(ext.PeerPort):       uint16(8080),
+		string(ext.HTTPStatusCode): uint16(http.StatusTeapot),
+	} {
+		if have := span.Tag(key); want != have {
+			t.Errorf("%s: want %v, have %v", key, want, have)
+		}
+	}
+}
+
+func TestToHTTPRequestLeavesOtherSpansUntagged(t *testing.T) {
+	tracer := mocktracer.New()
+	req, _ := http.NewRequest("GET", "/url", nil)
+	req.Host = "server.biz"
+	ctx := kitot.FromHTTPRequest(tracer, "server", nil)(context.Background(), req)
+	span := opentracing.SpanFromContext(ctx).(*mocktracer.MockSpan)
+
+	// The server calls another service without TraceClient.
+	out, _ := http.NewRequest("POST", "http://test.biz/other", nil)
+	kitot.ToHTTPRequest(tracer, nil)(ctx, out)
+	kitot.FromHTTPResponse()(ctx, &http.Response{StatusCode: http.StatusTeapot})
+
+	for key, want := range map[string]interface{}{
+		string(ext.SpanKind):       ext.SpanKindRPCServer,
+		string(ext.HTTPMethod):     "GET",
+		string(ext.HTTPUrl):        "http://server.biz/url",
+		string(ext.HTTPStatusCode): nil,
+	} {
+		if have := span.Tag(key); want != have {
+			t.Errorf("%s: want %v, have %v", key, want, have)
+		}
+	}
+}
+
+func TestFromHTTPRequestTags(t *testing.T) {
+	tracer := mocktracer.New()
+	req, _ := http.NewRequest("GET", "/url?q=1", nil)
+	req.Host = "test.biz"
+	req.RemoteAddr = "10.1.2.3:4567"
+
+	ctx := kitot.FromHTTPRequest(tracer, "op", nil)(context.Background(), req)
+	span := opentracing.SpanFromContext(ctx).(*mocktracer.MockSpan)
+
+	for key, want := range map[string]interface{}{
+		string(ext.SpanKind):     ext.SpanKindRPCServer,
+		string(ext.HTTPMethod):   "GET",
+		string(ext.HTTPUrl):      "http://test.biz/url?q=1",
+		string(ext.PeerHostname): "10.1.2.3",
+		string(ext.PeerPort):     uint16(4567),
+	} {
+		if have := span.Tag(key); want != have {
+			t.Errorf("%s: want %v, have %v", key, want, have)
+		}
+	}
+}
+
+func TestFinishHTTPServerSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	req, _ := http.NewRequest("GET", "/url", nil)
+	ctx := kitot.StartHTTPServerSpan(tracer, "server", nil)(context.Background(), req)
+	span := opentracing.SpanFromContext(ctx).(*mocktracer.MockSpan)
+
+	// TraceServer traces the endpoint in a child Span, leaving the server
+	// Span to the finalizer.
+	traced := kitot.TraceServer(tracer, "endpoint")(func(context.Context, interface{}) (interface{}, error) {
+		return struct{}{}, nil
+	})
+	if _, err := traced(ctx, struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 1, len(tracer.FinishedSpans); want != have {
+		t.Fatalf("Want %v span(s), found %v", want, have)
+	}
+	endpointSpan := tracer.FinishedSpans[0]
+	if want, have := "endpoint", endpointSpan.OperationName; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := span.SpanID, endpointSpan.ParentID; want != have {
+		t.Errorf("want ParentID %v, have %v", want, have)
+	}
+
+	var f kithttp.ServerFinalizerFunc = kitot.FinishHTTPServerSpan()
+	f(ctx, http.StatusBadRequest, 0, time.Millisecond, kithttp.DomainDecode)
+
+	if want, have := 2, len(tracer.FinishedSpans); want != have {
+		t.Fatalf("Want %v span(s), found %v", want, have)
+	}
+	if want, have := "server", span.OperationName; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := uint16(http.StatusBadRequest), span.Tag(string(ext.HTTPStatusCode)); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := true, span.Tag("error"); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,249 @@
+package zipkin
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// ErrBufferFull is returned by BatchCollector.Collect when spans are collected
+// faster than they can be sent.
+var ErrBufferFull = errors.New("zipkin: span buffer full")
+
+// ErrCollectorClosed is returned by BatchCollector.Collect after Close.
+var ErrCollectorClosed = errors.New("zipkin: collector closed")
+
+// Collector receives finished spans and forwards them to Zipkin.
+type Collector interface {
+	// Collect submits a finished span. Only sampled spans should be
+	// submitted.
+	Collect(*Span) error
+
+	// ShouldSample makes the sampling decision for a new trace, whose root
+	// span is given.
+	ShouldSample(*Span) bool
+
+	// Close flushes any pending spans and releases resources.
+	Close() error
+}
+
+// NopCollector discards all spans, and samples none.
+type NopCollector struct{}
+
+// Collect implements Collector.
+func (NopCollector) Collect(*Span) error { return nil }
+
+// ShouldSample implements Collector.
+func (NopCollector) ShouldSample(*Span) bool { return false }
+
+// Close implements Collector.
+func (NopCollector) Close() error { return nil }
+
+// InMemoryCollector keeps collected spans in memory. It samples every trace,
+// and is intended for tests.
+type InMemoryCollector struct {
+	mtx   sync.Mutex
+	spans []*Span
+}
+
+// NewInMemoryCollector returns a new, empty InMemoryCollector.
+func NewInMemoryCollector() *InMemoryCollector {
+	return &InMemoryCollector{}
+}
+
+// Collect implements Collector.
+func (c *InMemoryCollector) Collect(s *Span) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.spans = append(c.spans, s)
+	return nil
+}
+
+// ShouldSample implements Collector.
+func (c *InMemoryCollector) ShouldSample(*Span) bool { return true }
+
+// Close implements Collector.
+func (c *InMemoryCollector) Close() error { return nil }
+
+// Spans returns the spans collected so far, in order of collection.
+func (c *InMemoryCollector) Spans() []*Span {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return append([]*Span{}, c.spans...)
+}
+
+// Transport sends batches of spans to Zipkin, e.g. over HTTP, Kafka or
+// Scribe.
+type Transport interface {
+	Send(spans []*Span) error
+}
+
+// BatchCollector buffers spans, and sends them through a Transport in
+// batches, whenever the batch is full or the batch interval has elapsed.
+type BatchCollector struct {
+	transport     Transport
+	batchSize     int
+	batchInterval time.Duration
+	sampleRate    float64
+	logger        log.Logger
+	spanc         chan *Span
+	quitc         chan chan struct{}
+	closeOnce     sync.Once
+
+	// mtx guards closed, and ensures no span is sent on spanc once the
+	// loop has stopped draining it.
+	mtx    sync.RWMutex
+	closed bool
+}
+
+// NewBatchCollector returns a new BatchCollector that sends batches of spans
+// through the given transport.
+func NewBatchCollector(t Transport, options ...BatchCollectorOption) *BatchCollector {
+	c := &BatchCollector{
+		transport:     t,
+		batchSize:     100,
+		batchInterval: time.Second,
+		sampleRate:    1.0,
+		logger:        log.NewNopLogger(),
+		quitc:         make(chan chan struct{}),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.batchSize <= 0 {
+		c.batchSize = 100
+	}
+	if c.batchInterval <= 0 {
+		c.batchInterval = time.Second
+	}
+	c.spanc = make(chan *Span, 10*c.batchSize)
+	go c.loop()
+	return c
+}
+
+// BatchCollectorOption sets an optional parameter for the BatchCollector.
+type BatchCollectorOption func(*BatchCollector)
+
+// BatchSize sets the maximum number of spans per batch. By default, or if n
+// isn't positive, batches hold up to 100 spans.
+func BatchSize(n int) BatchCollectorOption {
+	return func(c *BatchCollector) { c.batchSize = n }
+}
+
+// BatchInterval sets the maximum time spans are held before they're sent.
+// By default, or if d isn't positive, spans are sent at least every second.
+func BatchInterval(d time.Duration) BatchCollectorOption {
+	return func(c *BatchCollector) { c.batchInterval = d }
+}
+
+// BatchSampleRate sets the fraction of new traces, between 0 and 1, that are
+// sampled. The decision is derived from the trace ID, so all services
+// sharing the same rate agree on it. By default, all traces are sampled.
+func BatchSampleRate(rate float64) BatchCollectorOption {
+	return func(c *BatchCollector) { c.sampleRate = rate }
+}
+
+// BatchLogger sets the logger used to report transport errors. By default,
+// no errors are logged.
+func BatchLogger(logger log.Logger) BatchCollectorOption {
+	return func(c *BatchCollector) { c.logger = logger }
+}
+
+// Collect implements Collector. It never blocks; if the buffer, which holds
+// up to ten batches, is full, the span is dropped and an error is returned.
+// After Close, spans are dropped and ErrCollectorClosed is returned.
+func (c *BatchCollector) Collect(s *Span) error {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	if c.closed {
+		return ErrCollectorClosed
+	}
+	select {
+	case c.spanc <- s:
+		return nil
+	default:
+		return ErrBufferFull
+	}
+}
+
+// ShouldSample implements Collector.
+func (c *BatchCollector) ShouldSample(s *Span) bool {
+	return shouldSample(s.TraceID(), c.sampleRate)
+}
+
+// Close implements Collector. It sends any buffered spans before returning.
+// Calls after the first have no effect.
+func (c *BatchCollector) Close() error {
+	c.closeOnce.Do(func() {
+		c.mtx.Lock()
+		c.closed = true
+		c.mtx.Unlock()
+
+		q := make(chan struct{})
+		c.quitc <- q
+		<-q
+	})
+	return nil
+}
+
+func (c *BatchCollector) loop() {
+	var (
+		batch  = make([]*Span, 0, c.batchSize)
+		ticker = time.NewTicker(c.batchInterval)
+	)
+	defer ticker.Stop()
+
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.transport.Send(batch); err != nil {
+			c.logger.Log("err", err, "dropped", len(batch))
+		}
+		batch = make([]*Span, 0, c.batchSize)
+	}
+
+	for {
+		select {
+		case s := <-c.spanc:
+			batch = append(batch, s)
+			if len(batch) >= c.batchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case q := <-c.quitc:
+			for drained := false; !drained; {
+				select {
+				case s := <-c.spanc:
+					batch = append(batch, s)
+					if len(batch) >= c.batchSize {
+						send()
+					}
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(q)
+			return
+		}
+	}
+}
+
+// shouldSample makes a sampling decision that is consistent for a trace ID.
+func shouldSample(traceID int64, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	if traceID < 0 {
+		traceID = -(traceID + 1)
+	}
+	return float64(traceID) < rate*math.MaxInt64
+}
@@ -0,0 +1,45 @@
+package zipkin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// HTTPTransport sends batches of spans as JSON to the /api/v1/spans endpoint
+// of a Zipkin collector.
+type HTTPTransport struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPTransport returns a Transport that POSTs spans to the given URL,
+// e.g. "http://zipkin:9411/api/v1/spans". If client is nil,
+// http.DefaultClient is used.
+func NewHTTPTransport(url string, client *http.Client) *HTTPTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPTransport{url: url, client: client}
+}
+
+// Send implements Transport.
+func (t *HTTPTransport) Send(spans []*Span) error {
+	body, err := json.Marshal(spans)
+	if err != nil {
+		return err
+	}
+	resp, err := t.client.Post(t.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("zipkin: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package zipkin
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Core annotation values, as defined by Zipkin.
+const (
+	ClientSend    = "cs"
+	ClientReceive = "cr"
+	ServerReceive = "sr"
+	ServerSend    = "ss"
+)
+
+// Span represents a Zipkin span, i.e. the work done by one host for one RPC.
+// Spans are safe for concurrent use.
+type Span struct {
+	host         hostEndpoint
+	methodName   string
+	traceIDHigh  int64
+	traceID      int64
+	spanID       int64
+	parentSpanID int64
+
+	mtx               sync.Mutex
+	sampled           bool
+	sampledSet        bool
+	annotations       []annotation
+	binaryAnnotations []binaryAnnotation
+}
+
+// NewSpan returns a new Span for the given host and trace identity. The
+// hostport should be a literal IP address and port; hostnames are recorded
+// without an address. A parentSpanID of zero means the span is a trace root.
+func NewSpan(hostport, serviceName, methodName string, traceID, spanID, parentSpanID int64) *Span {
+	return &Span{
+		host:         makeEndpoint(hostport, serviceName),
+		methodName:   methodName,
+		traceID:      traceID,
+		spanID:       spanID,
+		parentSpanID: parentSpanID,
+	}
+}
+
+// TraceID returns the ID of the trace the span belongs to. For 128-bit trace
+// IDs, it's the low 64 bits.
+func (s *Span) TraceID() int64 { return s.traceID }
+
+// TraceIDHigh returns the high 64 bits of a 128-bit trace ID, or zero for
+// 64-bit trace IDs.
+func (s *Span) TraceIDHigh() int64 { return s.traceIDHigh }
+
+// SpanID returns the ID of the span.
+func (s *Span) SpanID() int64 { return s.spanID }
+
+// ParentSpanID returns the ID of the parent span, or zero for trace roots.
+func (s *Span) ParentSpanID() int64 { return s.parentSpanID }
+
+// SetSampled records the sampling decision for the span's trace.
+func (s *Span) SetSampled(sampled bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.sampled, s.sampledSet = sampled, true
+}
+
+// Sampled returns the sampling decision for the span's trace. If no decision
+// has been made yet, ok is false.
+func (s *Span) Sampled() (sampled, ok bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.sampled, s.sampledSet
+}
+
+// Annotate records a timestamped event, such as one of the core annotations.
+func (s *Span) Annotate(value string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.annotations = append(s.annotations, annotation{timestamp: time.Now(), value: value})
+}
+
+// AnnotateString records a key/value pair, which Zipkin calls a binary
+// annotation of type string.
+func (s *Span) AnnotateString(key, value string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.binaryAnnotations = append(s.binaryAnnotations, binaryAnnotation{key: key, value: value})
+}
+
+// MarshalJSON encodes the span in the Zipkin v1 JSON format, as accepted by
+// the /api/v1/spans endpoint of a Zipkin collector.
+func (s *Span) MarshalJSON() ([]byte, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	type jsonAnnotation struct {
+		Timestamp int64         `json:"timestamp"`
+		Value     string        `json:"value"`
+		Endpoint  *hostEndpoint `json:"endpoint,omitempty"`
+	}
+	type jsonBinaryAnnotation struct {
+		Key      string        `json:"key"`
+		Value    string        `json:"value"`
+		Endpoint *hostEndpoint `json:"endpoint,omitempty"`
+	}
+	type jsonSpan struct {
+		TraceID           string                 `json:"traceId"`
+		Name              string                 `json:"name"`
+		ID                string                 `json:"id"`
+		ParentID          string                 `json:"parentId,omitempty"`
+		Timestamp         int64                  `json:"timestamp,omitempty"`
+		Duration          int64                  `json:"duration,omitempty"`
+		Annotations       []jsonAnnotation       `json:"annotations"`
+		BinaryAnnotations []jsonBinaryAnnotation `json:"binaryAnnotations"`
+	}
+
+	js := jsonSpan{
+		TraceID:           formatTraceID(s.traceIDHigh, s.traceID),
+		Name:              s.methodName,
+		ID:                formatID(s.spanID),
+		Annotations:       make([]jsonAnnotation, len(s.annotations)),
+		BinaryAnnotations: make([]jsonBinaryAnnotation, len(s.binaryAnnotations)),
+	}
+	if s.parentSpanID != 0 {
+		js.ParentID = formatID(s.parentSpanID)
+	}
+	var first, last time.Time
+	for i, a := range s.annotations {
+		js.Annotations[i] = jsonAnnotation{Timestamp: microseconds(a.timestamp), Value: a.value, Endpoint: &s.host}
+		if first.IsZero() || a.timestamp.Before(first) {
+			first = a.timestamp
+		}
+		if a.timestamp.After(last) {
+			last = a.timestamp
+		}
+	}
+	if !first.IsZero() {
+		js.Timestamp = microseconds(first)
+		js.Duration = last.Sub(first).Nanoseconds() / 1e3
+	}
+	for i, a := range s.binaryAnnotations {
+		js.BinaryAnnotations[i] = jsonBinaryAnnotation{Key: a.key, Value: a.value, Endpoint: &s.host}
+	}
+	return json.Marshal(js)
+}
+
+type annotation struct {
+	timestamp time.Time
+	value     string
+}
+
+type binaryAnnotation struct {
+	key   string
+	value string
+}
+
+// hostEndpoint is the network context of a span, which Zipkin calls an
+// endpoint.
+type hostEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4,omitempty"`
+	Port        uint16 `json:"port,omitempty"`
+}
+
+func makeEndpoint(hostport, serviceName string) hostEndpoint {
+	e := hostEndpoint{ServiceName: serviceName}
+	host, portStr, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return e
+	}
+	if ip := net.ParseIP(host).To4(); ip != nil {
+		e.IPv4 = ip.String()
+	}
+	if port, err := strconv.ParseUint(portStr, 10, 16); err == nil {
+		e.Port = uint16(port)
+	}
+	return e
+}
+
+func microseconds(t time.Time) int64 {
+	return t.UnixNano() / 1e3
+}
+
+// formatID encodes an ID as 16 lower-hex characters, as Zipkin expects.
+func formatID(id int64) string {
+	s := strconv.FormatUint(uint64(id), 16)
+	for len(s) < 16 {
+		s = "0" + s
+	}
+	return s
+}
+
+// parseID decodes an ID encoded by formatID, or any shorter hex encoding.
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseUint(s, 16, 64)
+	return int64(id), err
+}
+
+// formatTraceID encodes a trace ID like formatID, or as 32 lower-hex
+// characters if it has high bits.
+func formatTraceID(high, low int64) string {
+	if high == 0 {
+		return formatID(low)
+	}
+	return formatID(high) + formatID(low)
+}
+
+// parseTraceID is like parseID, but also accepts 128-bit trace IDs of up to
+// 32 hex characters, and returns their high and low 64 bits.
+func parseTraceID(s string) (high, low int64, err error) {
+	if len(s) > 16 && len(s) <= 32 {
+		if high, err = parseID(s[:len(s)-16]); err != nil {
+			return 0, 0, err
+		}
+		s = s[len(s)-16:]
+	}
+	low, err = parseID(s)
+	return high, low, err
+}
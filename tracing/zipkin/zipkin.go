@@ -0,0 +1,240 @@
+// Package zipkin provides native Zipkin tracing: B3 propagation over HTTP and
+// gRPC, endpoint middlewares that annotate spans, and span collectors.
+package zipkin
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+// B3 headers, used to propagate trace identity over HTTP. gRPC metadata uses
+// the lower-case forms.
+const (
+	TraceIDHTTPHeader      = "X-B3-TraceId"
+	SpanIDHTTPHeader       = "X-B3-SpanId"
+	ParentSpanIDHTTPHeader = "X-B3-ParentSpanId"
+	SampledHTTPHeader      = "X-B3-Sampled"
+)
+
+type contextKey int
+
+const spanContextKey contextKey = 0
+
+// NewContext returns a new context carrying the span.
+func NewContext(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanContextKey, s)
+}
+
+// FromContext returns the span carried by ctx, if any.
+func FromContext(ctx context.Context) (*Span, bool) {
+	s, ok := ctx.Value(spanContextKey).(*Span)
+	return s, ok
+}
+
+// NewSpanFunc takes a trace ID, span ID, and parent span ID, and returns a
+// span. A parent span ID of zero means the span is a trace root.
+type NewSpanFunc func(traceID, spanID, parentSpanID int64) *Span
+
+// MakeNewSpanFunc returns a NewSpanFunc that creates spans for the given
+// host and method.
+func MakeNewSpanFunc(hostport, serviceName, methodName string) NewSpanFunc {
+	return func(traceID, spanID, parentSpanID int64) *Span {
+		return NewSpan(hostport, serviceName, methodName, traceID, spanID, parentSpanID)
+	}
+}
+
+// FromHTTPRequest returns an http RequestFunc that joins the trace propagated
+// in the B3 headers of the request, or starts a new trace if there is none.
+// The span is incorporated in the returned context and can be retrieved with
+// FromContext. Use it in servers, together with AnnotateServer.
+//
+// The logger is used to report malformed headers and may be nil.
+func FromHTTPRequest(newSpan NewSpanFunc, logger log.Logger) kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return NewContext(ctx, fromCarrier(newSpan, logger, r.Header.Get))
+	}
+}
+
+// ToHTTPRequest returns an http RequestFunc that writes the B3 headers of the
+// span found in ctx to the request. If no span can be found, the RequestFunc
+// is a noop. Use it in clients, together with AnnotateClient.
+func ToHTTPRequest() kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if s, ok := FromContext(ctx); ok {
+			toCarrier(s, r.Header.Set)
+		}
+		return ctx
+	}
+}
+
+// FromGRPCRequest returns a grpc RequestFunc that joins the trace propagated
+// in the B3 metadata of the request, or starts a new trace if there is none.
+// The span is incorporated in the returned context and can be retrieved with
+// FromContext. Use it in servers, together with AnnotateServer.
+//
+// The logger is used to report malformed metadata and may be nil.
+func FromGRPCRequest(newSpan NewSpanFunc, logger log.Logger) grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		get := func(key string) string {
+			if vs := (*md)[grpcKey(key)]; len(vs) > 0 {
+				return vs[0]
+			}
+			return ""
+		}
+		return NewContext(ctx, fromCarrier(newSpan, logger, get))
+	}
+}
+
+// ToGRPCRequest returns a grpc RequestFunc that writes the B3 metadata of the
+// span found in ctx to the request. If no span can be found, the RequestFunc
+// is a noop. Use it in clients, together with AnnotateClient.
+func ToGRPCRequest() grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if s, ok := FromContext(ctx); ok {
+			toCarrier(s, func(key, val string) { (*md)[grpcKey(key)] = []string{val} })
+		}
+		return ctx
+	}
+}
+
+// AnnotateServer returns a server middleware that annotates the span found
+// in the context with server receive and server send, and collects it when
+// the endpoint returns. If the context has no span, a new trace is started.
+// Traces without a sampling decision are sampled according to the collector.
+func AnnotateServer(newSpan NewSpanFunc, c Collector) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			span, ok := FromContext(ctx)
+			if !ok {
+				traceID := newID()
+				span = newSpan(traceID, traceID, 0)
+				ctx = NewContext(ctx, span)
+			}
+			if _, ok := span.Sampled(); !ok {
+				span.SetSampled(c.ShouldSample(span))
+			}
+			span.Annotate(ServerReceive)
+			defer func() {
+				span.Annotate(ServerSend)
+				collect(c, span)
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+// AnnotateClient returns a client middleware that starts a child of the span
+// found in the context, or a new trace if there is none. The child span is
+// annotated with client send and client receive, incorporated in the context
+// passed to the next endpoint, so that ToHTTPRequest and ToGRPCRequest can
+// propagate it, and collected when the endpoint returns.
+func AnnotateClient(newSpan NewSpanFunc, c Collector) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			var span *Span
+			if parent, ok := FromContext(ctx); ok {
+				span = newSpan(parent.TraceID(), newID(), parent.SpanID())
+				span.traceIDHigh = parent.TraceIDHigh()
+				if sampled, ok := parent.Sampled(); ok {
+					span.SetSampled(sampled)
+				}
+			} else {
+				traceID := newID()
+				span = newSpan(traceID, traceID, 0)
+			}
+			if _, ok := span.Sampled(); !ok {
+				span.SetSampled(c.ShouldSample(span))
+			}
+			span.Annotate(ClientSend)
+			defer func() {
+				span.Annotate(ClientReceive)
+				collect(c, span)
+			}()
+			return next(NewContext(ctx, span), request)
+		}
+	}
+}
+
+func collect(c Collector, s *Span) {
+	if sampled, _ := s.Sampled(); sampled {
+		c.Collect(s)
+	}
+}
+
+// fromCarrier creates a span from the B3 values returned by get, or a new
+// trace root if they're missing or malformed.
+func fromCarrier(newSpan NewSpanFunc, logger log.Logger, get func(string) string) *Span {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	traceIDStr, spanIDStr := get(TraceIDHTTPHeader), get(SpanIDHTTPHeader)
+	if traceIDStr == "" || spanIDStr == "" {
+		traceID := newID()
+		return newSpan(traceID, traceID, 0)
+	}
+	traceIDHigh, traceID, err := parseTraceID(traceIDStr)
+	if err != nil {
+		logger.Log(TraceIDHTTPHeader, traceIDStr, "err", err)
+		traceID := newID()
+		return newSpan(traceID, traceID, 0)
+	}
+	spanID, err := parseID(spanIDStr)
+	if err != nil {
+		logger.Log(SpanIDHTTPHeader, spanIDStr, "err", err)
+		traceID := newID()
+		return newSpan(traceID, traceID, 0)
+	}
+	var parentSpanID int64
+	if s := get(ParentSpanIDHTTPHeader); s != "" {
+		if parentSpanID, err = parseID(s); err != nil {
+			logger.Log(ParentSpanIDHTTPHeader, s, "err", err)
+			parentSpanID = 0
+		}
+	}
+	span := newSpan(traceID, spanID, parentSpanID)
+	span.traceIDHigh = traceIDHigh
+	switch get(SampledHTTPHeader) {
+	case "1", "true":
+		span.SetSampled(true)
+	case "0", "false":
+		span.SetSampled(false)
+	}
+	return span
+}
+
+// toCarrier writes the B3 values of the span with set.
+func toCarrier(s *Span, set func(key, val string)) {
+	set(TraceIDHTTPHeader, formatTraceID(s.TraceIDHigh(), s.TraceID()))
+	set(SpanIDHTTPHeader, formatID(s.SpanID()))
+	if s.ParentSpanID() != 0 {
+		set(ParentSpanIDHTTPHeader, formatID(s.ParentSpanID()))
+	}
+	if sampled, ok := s.Sampled(); ok {
+		if sampled {
+			set(SampledHTTPHeader, "1")
+		} else {
+			set(SampledHTTPHeader, "0")
+		}
+	}
+}
+
+func grpcKey(header string) string {
+	return strings.ToLower(header)
+}
+
+func newID() int64 {
+	for {
+		if id := rand.Int63(); id != 0 {
+			return id
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package zipkin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/tracing/zipkin"
+)
+
+func TestHTTPRoundtrip(t *testing.T) {
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "my-service", "my-method")
+	parent := newSpan(12, 34, 56)
+	parent.SetSampled(true)
+
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	zipkin.ToHTTPRequest()(zipkin.NewContext(context.Background(), parent), r)
+	if want, have := "000000000000000c", r.Header.Get(zipkin.TraceIDHTTPHeader); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "1", r.Header.Get(zipkin.SampledHTTPHeader); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	ctx := zipkin.FromHTTPRequest(newSpan, nil)(context.Background(), r)
+	span, ok := zipkin.FromContext(ctx)
+	if !ok {
+		t.Fatal("no span in context")
+	}
+	testSpanIdentity(t, span, 12, 34, 56)
+	if sampled, ok := span.Sampled(); !ok || !sampled {
+		t.Errorf("want sampled, have sampled=%v ok=%v", sampled, ok)
+	}
+}
+
+func TestHTTPNewTrace(t *testing.T) {
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "my-service", "my-method")
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	r.Header.Set(zipkin.TraceIDHTTPHeader, "not-hex")
+	r.Header.Set(zipkin.SpanIDHTTPHeader, "1")
+
+	span, ok := zipkin.FromContext(zipkin.FromHTTPRequest(newSpan, nil)(context.Background(), r))
+	if !ok {
+		t.Fatal("no span in context")
+	}
+	if span.TraceID() == 0 || span.SpanID() != span.TraceID() || span.ParentSpanID() != 0 {
+		t.Errorf("want new root span, have %d/%d/%d", span.TraceID(), span.SpanID(), span.ParentSpanID())
+	}
+	if _, ok := span.Sampled(); ok {
+		t.Error("want no sampling decision")
+	}
+}
+
+func TestHTTP128BitTraceID(t *testing.T) {
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "my-service", "my-method")
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	r.Header.Set(zipkin.TraceIDHTTPHeader, "463ac35c9f6413ad48485a3953bb6124")
+	r.Header.Set(zipkin.SpanIDHTTPHeader, "22")
+
+	span, ok := zipkin.FromContext(zipkin.FromHTTPRequest(newSpan, nil)(context.Background(), r))
+	if !ok {
+		t.Fatal("no span in context")
+	}
+	testSpanIdentity(t, span, 0x48485a3953bb6124, 0x22, 0)
+	if want, have := int64(0x463ac35c9f6413ad), span.TraceIDHigh(); want != have {
+		t.Errorf("want trace ID high %x, have %x", want, have)
+	}
+
+	// The full trace ID is propagated to downstream services.
+	ctx := zipkin.NewContext(context.Background(), span)
+	out, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	zipkin.ToHTTPRequest()(ctx, out)
+	if want, have := "463ac35c9f6413ad48485a3953bb6124", out.Header.Get(zipkin.TraceIDHTTPHeader); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	md := metadata.MD{}
+	zipkin.ToGRPCRequest()(ctx, &md)
+	if want, have := []string{"463ac35c9f6413ad48485a3953bb6124"}, md["x-b3-traceid"]; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestGRPCRoundtrip(t *testing.T) {
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "my-service", "my-method")
+	parent := newSpan(12, 34, 56)
+	parent.SetSampled(false)
+
+	md := metadata.MD{}
+	zipkin.ToGRPCRequest()(zipkin.NewContext(context.Background(), parent), &md)
+	if want, have := []string{"0"}, md["x-b3-sampled"]; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	span, ok := zipkin.FromContext(zipkin.FromGRPCRequest(newSpan, nil)(context.Background(), &md))
+	if !ok {
+		t.Fatal("no span in context")
+	}
+	testSpanIdentity(t, span, 12, 34, 56)
+	if sampled, ok := span.Sampled(); !ok || sampled {
+		t.Errorf("want not sampled, have sampled=%v ok=%v", sampled, ok)
+	}
+}
+
+func TestAnnotateServerAndClient(t *testing.T) {
+	var (
+		c         = zipkin.NewInMemoryCollector()
+		newServer = zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "server-method")
+		newClient = zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "client-method")
+		client    *zipkin.Span
+	)
+	downstream := func(ctx context.Context, request interface{}) (interface{}, error) {
+		client, _ = zipkin.FromContext(ctx)
+		return request, nil
+	}
+	var e endpoint.Endpoint = zipkin.AnnotateClient(newClient, c)(downstream)
+	e = zipkin.AnnotateServer(newServer, c)(e)
+	if _, err := e(context.Background(), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := c.Spans()
+	if want, have := 2, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	server := spans[1]
+	if spans[0] != client {
+		t.Fatal("client span wasn't passed downstream")
+	}
+	testSpanIdentity(t, client, server.TraceID(), client.SpanID(), server.SpanID())
+
+	var js struct {
+		Name        string
+		ParentID    string
+		Annotations []struct {
+			Value    string
+			Endpoint struct {
+				ServiceName string
+				IPv4        string
+				Port        uint16
+			}
+		}
+	}
+	buf, err := json.Marshal(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf, &js); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "server-method", js.Name; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "", js.ParentID; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	var values []string
+	for _, a := range js.Annotations {
+		values = append(values, a.Value)
+	}
+	if want, have := "sr ss", strings.Join(values, " "); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "1.2.3.4", js.Annotations[0].Endpoint.IPv4; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestAnnotateServerNotSampled(t *testing.T) {
+	c := zipkin.NewInMemoryCollector()
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "method")
+	span := newSpan(1, 1, 0)
+	span.SetSampled(false)
+
+	nop := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+	e := zipkin.AnnotateServer(newSpan, c)(nop)
+	if _, err := e(zipkin.NewContext(context.Background(), span), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 0, len(c.Spans()); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestBatchCollector(t *testing.T) {
+	transport := &fakeTransport{}
+	c := zipkin.NewBatchCollector(transport, zipkin.BatchSize(2), zipkin.BatchInterval(time.Hour))
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "method")
+	for i := int64(1); i <= 3; i++ {
+		if err := c.Collect(newSpan(i, i, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := []int{2, 1}, transport.batchSizes(); len(want) != len(have) || want[0] != have[0] || want[1] != have[1] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestBatchCollectorDefaultsAndDoubleClose(t *testing.T) {
+	transport := &fakeTransport{}
+	c := zipkin.NewBatchCollector(transport, zipkin.BatchSize(0), zipkin.BatchInterval(0))
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "method")
+	if err := c.Collect(newSpan(1, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.Close()
+		c.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close didn't return")
+	}
+	if want, have := []int{1}, transport.batchSizes(); len(want) != len(have) || want[0] != have[0] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := zipkin.ErrCollectorClosed, c.Collect(newSpan(2, 2, 0)); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestBatchSampleRate(t *testing.T) {
+	newSpan := zipkin.MakeNewSpanFunc("1.2.3.4:1234", "server", "method")
+	for _, tc := range []struct {
+		rate float64
+		want bool
+	}{
+		{0, false},
+		{1, true},
+	} {
+		c := zipkin.NewBatchCollector(&fakeTransport{}, zipkin.BatchSampleRate(tc.rate))
+		if have := c.ShouldSample(newSpan(42, 42, 0)); tc.want != have {
+			t.Errorf("rate %v: want %v, have %v", tc.rate, tc.want, have)
+		}
+		c.Close()
+	}
+}
+
+func testSpanIdentity(t *testing.T, span *zipkin.Span, traceID, spanID, parentSpanID int64) {
+	if want, have := traceID, span.TraceID(); want != have {
+		t.Errorf("trace ID: want %d, have %d", want, have)
+	}
+	if want, have := spanID, span.SpanID(); want != have {
+		t.Errorf("span ID: want %d, have %d", want, have)
+	}
+	if want, have := parentSpanID, span.ParentSpanID(); want != have {
+		t.Errorf("parent span ID: want %d, have %d", want, have)
+	}
+}
+
+type fakeTransport struct {
+	mtx     sync.Mutex
+	batches [][]*zipkin.Span
+}
+
+func (t *fakeTransport) Send(spans []*zipkin.Span) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.batches = append(t.batches, spans)
+	return nil
+}
+
+func (t *fakeTransport) batchSizes() []int {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	sizes := make([]int, len(t.batches))
+	for i, b := range t.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
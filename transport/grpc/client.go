@@ -14,18 +14,24 @@ import (
 // Client wraps a gRPC connection and provides a method that implements
 // endpoint.Endpoint.
 type Client struct {
-	client      *grpc.ClientConn
-	serviceName string
-	method      string
-	enc         EncodeRequestFunc
-	dec         DecodeResponseFunc
-	grpcReply   reflect.Type
-	before      []RequestFunc
+	client       *grpc.ClientConn
+	serviceName  string
+	method       string
+	enc          EncodeRequestFunc
+	dec          DecodeResponseFunc
+	grpcReply    reflect.Type
+	before       []RequestFunc
+	after        []ClientResponseFunc
+	callOptions  []grpc.CallOption
+	errorDecoder ErrorDecoder
 }
 
 // NewClient constructs a usable Client for a single remote endpoint.
 // Pass an zero-value Protobuf message of the RPC response type as
 // the grpcReply argument.
+//
+// The service is assumed to live in the "pb" proto package. To call services
+// in other packages, or in none, use ClientMethodPath.
 func NewClient(
 	cc *grpc.ClientConn,
 	serviceName string,
@@ -49,7 +55,9 @@ func NewClient(
 				reflect.ValueOf(grpcReply),
 			).Interface(),
 		),
-		before: []RequestFunc{},
+		before:       []RequestFunc{},
+		after:        []ClientResponseFunc{},
+		errorDecoder: defaultErrorDecoder,
 	}
 	for _, option := range options {
 		option(c)
@@ -66,6 +74,35 @@ func SetClientBefore(before ...RequestFunc) ClientOption {
 	return func(c *Client) { c.before = before }
 }
 
+// ClientMethodPath sets the full path of the RPC, e.g.
+// "/pkg.subpkg.Service/Method", overriding the one NewClient makes from the
+// service and method names.
+func ClientMethodPath(path string) ClientOption {
+	return func(c *Client) { c.method = path }
+}
+
+// ClientAfter sets the ClientResponseFuncs that are applied to the incoming
+// gRPC response header and trailer metadata after the RPC returns, prior to
+// the response or error being decoded. They run for failed RPCs too, so that
+// they can see the trailer.
+func ClientAfter(after ...ClientResponseFunc) ClientOption {
+	return func(c *Client) { c.after = after }
+}
+
+// ClientCallOptions sets the grpc.CallOptions that are passed along with
+// every invocation of the RPC.
+func ClientCallOptions(opts ...grpc.CallOption) ClientOption {
+	return func(c *Client) { c.callOptions = opts }
+}
+
+// ClientErrorDecoder is used to turn errors returned by the gRPC invocation
+// into the errors returned from the endpoint. By default, Canceled and
+// DeadlineExceeded statuses are decoded to the corresponding context errors,
+// and all other statuses to a StatusError.
+func ClientErrorDecoder(ed ErrorDecoder) ClientOption {
+	return func(c *Client) { c.errorDecoder = ed }
+}
+
 // Endpoint returns a usable endpoint that will invoke the gRPC specified by the
 // client.
 func (c Client) Endpoint() endpoint.Endpoint {
@@ -89,9 +126,17 @@ func (c Client) Endpoint() endpoint.Endpoint {
 			ctx = metadata.NewOutgoingContext(ctx, mdWithApiKey)
 		}
 
+		var header, trailer metadata.MD
+		opts := append([]grpc.CallOption{grpc.Header(&header), grpc.Trailer(&trailer)}, c.callOptions...)
 		grpcReply := reflect.New(c.grpcReply).Interface()
-		if err = grpc.Invoke(ctx, c.method, req, grpcReply, c.client); err != nil {
-			return nil, fmt.Errorf("Invoke: %v", err)
+		err = grpc.Invoke(ctx, c.method, req, grpcReply, c.client, opts...)
+
+		for _, f := range c.after {
+			ctx = f(ctx, header, trailer)
+		}
+
+		if err != nil {
+			return nil, c.errorDecoder(ctx, err)
 		}
 
 		response, err := c.dec(ctx, grpcReply)
@@ -0,0 +1,168 @@
+package grpc_test
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+)
+
+// stringCodec lets the test server and client exchange plain strings, in
+// place of Protobuf messages.
+type stringCodec struct{}
+
+func (stringCodec) Marshal(v interface{}) ([]byte, error) { return []byte(*v.(*string)), nil }
+
+func (stringCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func (stringCodec) String() string { return "string" }
+
+// startServer serves an Upper method on the package-less Echo service. It
+// echoes the "x-request-id" metadata in the header, and sets the "x-trailer"
+// trailer. Empty strings fail with InvalidArgument.
+func startServer(t *testing.T) (*grpc.ClientConn, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.CustomCodec(stringCodec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "Echo",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Upper",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var s string
+				if err := dec(&s); err != nil {
+					return nil, err
+				}
+				md, _ := metadata.FromContext(ctx)
+				grpc.SetHeader(ctx, metadata.Pairs("x-request-id", strings.Join(md["x-request-id"], "")))
+				grpc.SetTrailer(ctx, metadata.Pairs("x-trailer", "done"))
+				if s == "" {
+					return nil, grpc.Errorf(codes.InvalidArgument, "empty")
+				}
+				reply := strings.ToUpper(s)
+				return &reply, nil
+			},
+		}},
+	}, struct{}{})
+	go server.Serve(ln)
+
+	cc, err := grpc.Dial(ln.Addr().String(), grpc.WithInsecure(), grpc.WithCodec(stringCodec{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cc, func() { cc.Close(); server.Stop() }
+}
+
+func TestClient(t *testing.T) {
+	cc, stop := startServer(t)
+	defer stop()
+
+	type key int
+	var header, trailer metadata.MD
+	client := grpctransport.NewClient(
+		cc,
+		"Echo",
+		"Upper",
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			s := request.(string)
+			return &s, nil
+		},
+		func(ctx context.Context, reply interface{}) (interface{}, error) {
+			if want, have := "abc", ctx.Value(key(0)); want != have {
+				t.Errorf("context: want %q, have %v", want, have)
+			}
+			return *reply.(*string), nil
+		},
+		"",
+		grpctransport.ClientMethodPath("/Echo/Upper"),
+		grpctransport.SetClientBefore(func(ctx context.Context, md *metadata.MD) context.Context {
+			(*md)["x-request-id"] = []string{"abc"}
+			return ctx
+		}),
+		grpctransport.ClientAfter(func(ctx context.Context, h metadata.MD, tr metadata.MD) context.Context {
+			header, trailer = h, tr
+			return context.WithValue(ctx, key(0), strings.Join(h["x-request-id"], ""))
+		}),
+		grpctransport.ClientCallOptions(grpc.FailFast(false)),
+	)
+
+	response, err := client.Endpoint()(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "HELLO", response; want != have {
+		t.Errorf("want %q, have %v", want, have)
+	}
+	if want, have := "abc", strings.Join(header["x-request-id"], ""); want != have {
+		t.Errorf("header: want %q, have %q", want, have)
+	}
+	if want, have := "done", strings.Join(trailer["x-trailer"], ""); want != have {
+		t.Errorf("trailer: want %q, have %q", want, have)
+	}
+}
+
+func TestClientAfterOnError(t *testing.T) {
+	cc, stop := startServer(t)
+	defer stop()
+
+	var trailer metadata.MD
+	client := grpctransport.NewClient(
+		cc,
+		"Echo",
+		"Upper",
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			s := request.(string)
+			return &s, nil
+		},
+		func(_ context.Context, reply interface{}) (interface{}, error) { return *reply.(*string), nil },
+		"",
+		grpctransport.ClientMethodPath("/Echo/Upper"),
+		grpctransport.ClientAfter(func(ctx context.Context, _ metadata.MD, tr metadata.MD) context.Context {
+			trailer = tr
+			return ctx
+		}),
+	)
+
+	_, err := client.Endpoint()(context.Background(), "")
+	if want, have := (grpctransport.StatusError{Code: codes.InvalidArgument, Desc: "empty"}), err; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := "done", strings.Join(trailer["x-trailer"], ""); want != have {
+		t.Errorf("trailer: want %q, have %q", want, have)
+	}
+}
+
+func TestClientDefaultMethodPath(t *testing.T) {
+	cc, stop := startServer(t)
+	defer stop()
+
+	// Without ClientMethodPath, the service is looked up in the pb package,
+	// where the test server has none.
+	client := grpctransport.NewClient(
+		cc,
+		"Echo",
+		"Upper",
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			s := request.(string)
+			return &s, nil
+		},
+		func(_ context.Context, reply interface{}) (interface{}, error) { return *reply.(*string), nil },
+		"",
+	)
+	_, err := client.Endpoint()(context.Background(), "hello")
+	if want, have := codes.Unimplemented, err.(grpctransport.StatusError).Code; want != have {
+		t.Errorf("want %s, have %v", want, err)
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// StatusCoder is checked by the default server error encoder. If an error
+// value implements StatusCoder, its StatusCode is sent to the client as the
+// gRPC status code.
+type StatusCoder interface {
+	StatusCode() codes.Code
+}
+
+// StatusError is a gRPC status returned by a remote server. It is produced by
+// the default client error decoder, and understood by the default server
+// error encoder, so statuses survive being proxied through Go kit services.
+type StatusError struct {
+	Code codes.Code
+	Desc string
+}
+
+// Error implements the error interface.
+func (e StatusError) Error() string {
+	return fmt.Sprintf("rpc error: code = %s desc = %s", e.Code, e.Desc)
+}
+
+// StatusCode implements StatusCoder.
+func (e StatusError) StatusCode() codes.Code {
+	return e.Code
+}
+
+// ErrorEncoder is responsible for turning an error encountered by a Server
+// into the error that is returned to gRPC, which should carry an appropriate
+// status code.
+type ErrorEncoder func(ctx context.Context, err error) error
+
+// ErrorDecoder is responsible for turning an error returned by the gRPC
+// invocation in a Client into the error returned from the endpoint.
+type ErrorDecoder func(ctx context.Context, err error) error
+
+func defaultErrorEncoder(_ context.Context, err error) error {
+	switch e := err.(type) {
+	case StatusError:
+		return grpc.Errorf(e.Code, "%s", e.Desc)
+	case StatusCoder:
+		return grpc.Errorf(e.StatusCode(), "%s", err.Error())
+	case BadRequestError:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	switch err {
+	case context.Canceled:
+		return grpc.Errorf(codes.Canceled, "%s", err.Error())
+	case context.DeadlineExceeded:
+		return grpc.Errorf(codes.DeadlineExceeded, "%s", err.Error())
+	}
+	if grpc.Code(err) != codes.Unknown {
+		return err // already a gRPC status
+	}
+	return grpc.Errorf(codes.Unknown, "%s", err.Error())
+}
+
+func defaultErrorDecoder(_ context.Context, err error) error {
+	switch code := grpc.Code(err); code {
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return StatusError{Code: code, Desc: grpc.ErrorDesc(err)}
+	}
+}
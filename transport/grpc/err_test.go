@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDefaultErrorEncoder(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{errors.New("dang"), codes.Unknown},
+		{BadRequestError{errors.New("dang")}, codes.InvalidArgument},
+		{context.Canceled, codes.Canceled},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{StatusError{Code: codes.NotFound, Desc: "dang"}, codes.NotFound},
+		{grpc.Errorf(codes.PermissionDenied, "dang"), codes.PermissionDenied},
+	} {
+		if want, have := tc.code, grpc.Code(defaultErrorEncoder(context.Background(), tc.err)); want != have {
+			t.Errorf("%v: want %s, have %s", tc.err, want, have)
+		}
+	}
+}
+
+func TestErrorRoundTrip(t *testing.T) {
+	for _, err := range []error{
+		context.Canceled,
+		context.DeadlineExceeded,
+		StatusError{Code: codes.NotFound, Desc: "dang"},
+	} {
+		ctx := context.Background()
+		if want, have := err, defaultErrorDecoder(ctx, defaultErrorEncoder(ctx, err)); want != have {
+			t.Errorf("want %v, have %v", want, have)
+		}
+	}
+}
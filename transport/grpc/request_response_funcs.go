@@ -23,6 +23,12 @@ type RequestFunc func(context.Context, *metadata.MD) context.Context
 // servers, after invoking the endpoint but prior to writing a response.
 type ResponseFunc func(context.Context, *metadata.MD)
 
+// ClientResponseFunc may take information from the header and trailer
+// metadata of a gRPC response and put it into a request context.
+// ClientResponseFuncs are only executed in clients, after the RPC has been
+// invoked, successfully or not, but prior to the response being decoded.
+type ClientResponseFunc func(ctx context.Context, header metadata.MD, trailer metadata.MD) context.Context
+
 // SetResponseHeader returns a ResponseFunc that sets the specified metadata
 // key-value pair.
 func SetResponseHeader(key, val string) ResponseFunc {
@@ -51,3 +57,17 @@ func EncodeKeyValue(key, val string) (string, string) {
 	}
 	return key, val
 }
+
+// DecodeKeyValue reverses EncodeKeyValue, decoding the value of binary
+// metadata headers.
+func DecodeKeyValue(key, val string) (string, string, error) {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, binHdrSuffix) {
+		v, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return key, "", err
+		}
+		val = string(v)
+	}
+	return key, val, nil
+}
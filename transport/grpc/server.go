@@ -16,13 +16,14 @@ type Handler interface {
 
 // Server wraps an endpoint and implements grpc.Handler.
 type Server struct {
-	ctx    context.Context
-	e      endpoint.Endpoint
-	dec    DecodeRequestFunc
-	enc    EncodeResponseFunc
-	before []RequestFunc
-	after  []ResponseFunc
-	logger log.Logger
+	ctx          context.Context
+	e            endpoint.Endpoint
+	dec          DecodeRequestFunc
+	enc          EncodeResponseFunc
+	before       []RequestFunc
+	after        []ResponseFunc
+	errorEncoder ErrorEncoder
+	logger       log.Logger
 }
 
 // NewServer constructs a new server, which implements grpc.Server and wraps
@@ -35,11 +36,12 @@ func NewServer(
 	options ...ServerOption,
 ) *Server {
 	s := &Server{
-		ctx:    ctx,
-		e:      e,
-		dec:    dec,
-		enc:    enc,
-		logger: log.NewNopLogger(),
+		ctx:          ctx,
+		e:            e,
+		dec:          dec,
+		enc:          enc,
+		errorEncoder: defaultErrorEncoder,
+		logger:       log.NewNopLogger(),
 	}
 	for _, option := range options {
 		option(s)
@@ -62,6 +64,15 @@ func ServerAfter(after ...ResponseFunc) ServerOption {
 	return func(s *Server) { s.after = after }
 }
 
+// ServerErrorEncoder is used to turn errors encountered in the processing of a
+// request into the errors returned to gRPC. By default, errors are mapped to
+// gRPC status codes: BadRequestError to InvalidArgument, context cancelation
+// and deadline errors to Canceled and DeadlineExceeded, StatusCoder values to
+// their StatusCode, and anything else to Unknown.
+func ServerErrorEncoder(ee ErrorEncoder) ServerOption {
+	return func(s *Server) { s.errorEncoder = ee }
+}
+
 // ServerErrorLogger is used to log non-terminal errors. By default, no errors
 // are logged.
 func ServerErrorLogger(logger log.Logger) ServerOption {
@@ -89,13 +100,13 @@ func (s Server) ServeGRPC(grpcCtx context.Context, r interface{}) (context.Conte
 	request, err := s.dec(grpcCtx, r)
 	if err != nil {
 		s.logger.Log("err", err)
-		return grpcCtx, nil, BadRequestError{err}
+		return grpcCtx, nil, s.errorEncoder(ctx, BadRequestError{err})
 	}
 
 	response, err := s.e(ctx, request)
 	if err != nil {
 		s.logger.Log("err", err)
-		return grpcCtx, nil, err
+		return grpcCtx, nil, s.errorEncoder(ctx, err)
 	}
 
 	for _, f := range s.after {
@@ -108,7 +119,7 @@ func (s Server) ServeGRPC(grpcCtx context.Context, r interface{}) (context.Conte
 	grpcResp, err := s.enc(grpcCtx, response)
 	if err != nil {
 		s.logger.Log("err", err)
-		return grpcCtx, nil, err
+		return grpcCtx, nil, s.errorEncoder(ctx, err)
 	}
 	return grpcCtx, grpcResp, nil
 }
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"io"
 	"net/http"
 	"net/url"
+	"sync/atomic"
 
 	"golang.org/x/net/context"
 	"golang.org/x/net/context/ctxhttp"
@@ -18,6 +20,8 @@ type Client struct {
 	enc            EncodeRequestFunc
 	dec            DecodeResponseFunc
 	before         []RequestFunc
+	after          []ClientResponseFunc
+	finalizer      ClientFinalizerFunc
 	bufferedStream bool
 }
 
@@ -36,6 +40,7 @@ func NewClient(
 		enc:            enc,
 		dec:            dec,
 		before:         []RequestFunc{},
+		after:          []ClientResponseFunc{},
 		bufferedStream: false,
 	}
 	for _, option := range options {
@@ -59,6 +64,20 @@ func SetClientBefore(before ...RequestFunc) ClientOption {
 	return func(c *Client) { c.before = before }
 }
 
+// ClientAfter sets the ClientResponseFuncs that are applied to the incoming
+// HTTP response prior to it being decoded. This is useful for obtaining
+// anything off of the response and adding it into the context prior to
+// decoding.
+func ClientAfter(after ...ClientResponseFunc) ClientOption {
+	return func(c *Client) { c.after = after }
+}
+
+// ClientFinalizer is executed at the end of every HTTP request, whether it
+// succeeded or not. By default, no finalizer is registered.
+func ClientFinalizer(f ClientFinalizerFunc) ClientOption {
+	return func(c *Client) { c.finalizer = f }
+}
+
 // SetBufferedStream sets whether the Response.Body is left open, allowing it
 // to be read from later. Useful for transporting a file as a buffered stream.
 func SetBufferedStream(buffered bool) ClientOption {
@@ -68,10 +87,24 @@ func SetBufferedStream(buffered bool) ClientOption {
 // Endpoint returns a usable endpoint that will invoke the RPC specified by
 // the client.
 func (c Client) Endpoint() endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
+		var (
+			resp              *http.Response
+			reqBody, respBody *countingReadCloser
+		)
+		if c.finalizer != nil {
+			defer func() {
+				var code int
+				if resp != nil {
+					code = resp.StatusCode
+				}
+				c.finalizer(ctx, code, reqBody.count(), respBody.count(), err)
+			}()
+		}
+
 		req, err := http.NewRequest(c.method, c.tgt.String(), nil)
 		if err != nil {
 			return nil, Error{Domain: DomainNewRequest, Err: err}
@@ -80,20 +113,32 @@ func (c Client) Endpoint() endpoint.Endpoint {
 		if err = c.enc(ctx, req, request); err != nil {
 			return nil, Error{Domain: DomainEncode, Err: err}
 		}
+		if c.finalizer != nil && req.Body != nil {
+			reqBody = &countingReadCloser{ReadCloser: req.Body}
+			req.Body = reqBody
+		}
 
 		for _, f := range c.before {
 			ctx = f(ctx, req)
 		}
 
-		resp, err := ctxhttp.Do(ctx, c.client, req)
+		resp, err = ctxhttp.Do(ctx, c.client, req)
 		if err != nil {
 			return nil, Error{Domain: DomainDo, Err: err}
 		}
+		if c.finalizer != nil {
+			respBody = &countingReadCloser{ReadCloser: resp.Body}
+			resp.Body = respBody
+		}
 		if !c.bufferedStream {
 			defer resp.Body.Close()
 		}
 
-		response, err := c.dec(ctx, resp)
+		for _, f := range c.after {
+			ctx = f(ctx, resp)
+		}
+
+		response, err = c.dec(ctx, resp)
 		if err != nil {
 			return nil, Error{Domain: DomainDecode, Err: err}
 		}
@@ -101,3 +146,33 @@ func (c Client) Endpoint() endpoint.Endpoint {
 		return response, nil
 	}
 }
+
+// ClientFinalizerFunc can be used to perform work at the end of a client HTTP
+// request, after the response has been decoded or an error has occurred. It
+// receives the status code of the response (zero if no response was
+// received), the number of request body bytes sent, the number of response
+// body bytes read, and the error returned by the endpoint, if any. With a
+// buffered stream, only the response bytes read during decoding are counted.
+type ClientFinalizerFunc func(ctx context.Context, code int, requestSize, responseSize int64, err error)
+
+// countingReadCloser counts the bytes read through it. It is read by the HTTP
+// transport in a different goroutine, so the count is kept atomically.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+// count returns the number of bytes read so far. It is safe to call on a nil
+// receiver, which has read zero bytes.
+func (r *countingReadCloser) count() int64 {
+	if r == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&r.n)
+}
@@ -1,10 +1,13 @@
 package http_test
 
 import (
+	"errors"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -126,6 +129,136 @@ func TestHTTPClientBufferedStream(t *testing.T) {
 	}
 }
 
+func TestHTTPClientAfter(t *testing.T) {
+	type key int
+	const (
+		limitKey  key = 0
+		headerKey     = "X-Rate-Limit-Remaining"
+		headerVal     = "42"
+	)
+	var (
+		encode = func(context.Context, *http.Request, interface{}) error { return nil }
+		decode = func(ctx context.Context, _ *http.Response) (interface{}, error) {
+			return ctx.Value(limitKey), nil
+		}
+		after = func(ctx context.Context, r *http.Response) context.Context {
+			return context.WithValue(ctx, limitKey, r.Header.Get(headerKey))
+		}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headerKey, headerVal)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := httptransport.NewClient(
+		"GET",
+		mustParse(server.URL),
+		encode,
+		decode,
+		httptransport.ClientAfter(after),
+	)
+
+	res, err := client.Endpoint()(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := headerVal, res; want != have {
+		t.Errorf("want %q, have %v", want, have)
+	}
+}
+
+func TestHTTPClientFinalizer(t *testing.T) {
+	const (
+		reqBody  = "hello"
+		respBody = "hello, world"
+	)
+	type result struct {
+		code                      int
+		requestSize, responseSize int64
+		err                       error
+	}
+	var (
+		encode = func(_ context.Context, r *http.Request, _ interface{}) error {
+			r.Body = ioutil.NopCloser(strings.NewReader(reqBody))
+			return nil
+		}
+		decode = func(_ context.Context, r *http.Response) (interface{}, error) {
+			_, err := ioutil.ReadAll(r.Body)
+			return nil, err
+		}
+		results   = make(chan result, 1)
+		finalizer = func(_ context.Context, code int, requestSize, responseSize int64, err error) {
+			results <- result{code, requestSize, responseSize, err}
+		}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(respBody))
+	}))
+	defer server.Close()
+
+	client := httptransport.NewClient(
+		"POST",
+		mustParse(server.URL),
+		encode,
+		decode,
+		httptransport.ClientFinalizer(finalizer),
+	)
+
+	if _, err := client.Endpoint()(context.Background(), struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+	have := <-results
+	if want := (result{http.StatusAccepted, int64(len(reqBody)), int64(len(respBody)), nil}); want != have {
+		t.Errorf("want %+v, have %+v", want, have)
+	}
+}
+
+func TestHTTPClientFinalizerError(t *testing.T) {
+	var (
+		errDecode = errors.New("decode")
+		encode    = func(context.Context, *http.Request, interface{}) error { return nil }
+		decode    = func(context.Context, *http.Response) (interface{}, error) { return nil, errDecode }
+		codes     = make(chan int, 1)
+		errs      = make(chan error, 1)
+		finalizer = func(_ context.Context, code int, _, _ int64, err error) {
+			codes <- code
+			errs <- err
+		}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	client := httptransport.NewClient(
+		"GET",
+		mustParse(server.URL),
+		encode,
+		decode,
+		httptransport.ClientFinalizer(finalizer),
+	)
+
+	if _, err := client.Endpoint()(context.Background(), struct{}{}); err == nil {
+		t.Fatal("want error, have none")
+	}
+	if want, have := http.StatusTeapot, <-codes; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	err, ok := (<-errs).(httptransport.Error)
+	if !ok {
+		t.Fatalf("want httptransport.Error, have %T", err)
+	}
+	if want, have := errDecode, err.Err; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
 func mustParse(s string) *url.URL {
 	u, err := url.Parse(s)
 	if err != nil {
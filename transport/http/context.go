@@ -0,0 +1,41 @@
+package http
+
+import (
+	"golang.org/x/net/context"
+)
+
+// joinContexts returns a context that carries the values of both base and req,
+// and is done as soon as either of them is done. The earlier of their
+// deadlines, if any, applies. When a key is present in both, the value from
+// req wins.
+func joinContexts(base, req context.Context) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if deadline, ok := req.Deadline(); ok {
+		ctx, cancel = context.WithDeadline(base, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(base)
+	}
+	go func() {
+		select {
+		case <-req.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return joinedContext{Context: ctx, req: req}, cancel
+}
+
+type joinedContext struct {
+	context.Context
+	req context.Context
+}
+
+func (c joinedContext) Value(key interface{}) interface{} {
+	if v := c.req.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
+	"reflect"
 
 	"golang.org/x/net/context"
 )
@@ -29,3 +33,64 @@ type EncodeResponseFunc func(context.Context, http.ResponseWriter, interface{})
 // endpoints. One straightforward DecodeResponseFunc could be something that
 // JSON decodes from the response body to the concrete response type.
 type DecodeResponseFunc func(context.Context, *http.Response) (response interface{}, err error)
+
+// DefaultMaxRequestBodySize is the largest request body, in bytes, that
+// DecodeJSONRequest will read.
+const DefaultMaxRequestBodySize = 1 << 20
+
+// EncodeJSONRequest is an EncodeRequestFunc that serializes the request as a
+// JSON object to the request body, and sets the Content-Type and
+// Content-Length headers accordingly.
+func EncodeJSONRequest(_ context.Context, r *http.Request, request interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	r.ContentLength = int64(buf.Len())
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// EncodeJSONResponse is an EncodeResponseFunc that serializes the response as
+// a JSON object to the ResponseWriter, and sets the Content-Type header
+// accordingly.
+func EncodeJSONResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(response)
+}
+
+// DecodeJSONRequest returns a DecodeRequestFunc that deserializes a JSON
+// request body into a new value of the same type as prototype. If prototype
+// is a pointer, a pointer to a new value is returned; otherwise the value
+// itself is. Bodies larger than DefaultMaxRequestBodySize are rejected.
+// Failures are returned as an Error with DomainDecode. It panics if prototype
+// is nil.
+func DecodeJSONRequest(prototype interface{}) DecodeRequestFunc {
+	return DecodeJSONRequestLimit(prototype, DefaultMaxRequestBodySize)
+}
+
+// DecodeJSONRequestLimit is like DecodeJSONRequest, but rejects request bodies
+// larger than maxBytes. It panics if prototype is nil, since there's no type
+// to decode into.
+func DecodeJSONRequestLimit(prototype interface{}, maxBytes int64) DecodeRequestFunc {
+	if prototype == nil {
+		panic("DecodeJSONRequest: prototype must not be nil")
+	}
+	t := reflect.TypeOf(prototype)
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		v := reflect.New(t)
+		body := http.MaxBytesReader(nil, r.Body, maxBytes)
+		if err := json.NewDecoder(body).Decode(v.Interface()); err != nil {
+			return nil, Error{Domain: DomainDecode, Err: err}
+		}
+		if isPtr {
+			return v.Interface(), nil
+		}
+		return v.Elem().Interface(), nil
+	}
+}
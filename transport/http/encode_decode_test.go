@@ -0,0 +1,106 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+type echoRequest struct {
+	S string `json:"s"`
+}
+
+func TestDecodeJSONRequest(t *testing.T) {
+	for prototype, want := range map[interface{}]interface{}{
+		echoRequest{}:  echoRequest{"hello"},
+		&echoRequest{}: &echoRequest{"hello"},
+	} {
+		req, _ := http.NewRequest("POST", "/", strings.NewReader(`{"s":"hello"}`))
+		have, err := httptransport.DecodeJSONRequest(prototype)(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("want %#v, have %#v", want, have)
+		}
+	}
+}
+
+func TestDecodeJSONRequestNilPrototype(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic, have none")
+		}
+	}()
+	httptransport.DecodeJSONRequest(nil)
+}
+
+func TestDecodeJSONRequestLimit(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(`{"s":"hello"}`))
+	_, err := httptransport.DecodeJSONRequestLimit(echoRequest{}, 4)(context.Background(), req)
+	e, ok := err.(httptransport.Error)
+	if !ok {
+		t.Fatalf("want httptransport.Error, have %T (%v)", err, err)
+	}
+	if want, have := httptransport.DomainDecode, e.Domain; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	server := httptest.NewServer(httptransport.NewServer(
+		context.Background(),
+		func(_ context.Context, request interface{}) (interface{}, error) { return request, nil },
+		httptransport.DecodeJSONRequest(echoRequest{}),
+		httptransport.EncodeJSONResponse,
+	))
+	defer server.Close()
+
+	contentTypes := make(chan string, 1)
+	client := httptransport.NewClient(
+		"POST",
+		mustParse(server.URL),
+		httptransport.EncodeJSONRequest,
+		func(_ context.Context, r *http.Response) (interface{}, error) {
+			contentTypes <- r.Header.Get("Content-Type")
+			var response echoRequest
+			err := json.NewDecoder(r.Body).Decode(&response)
+			return response, err
+		},
+	)
+
+	have, err := client.Endpoint()(context.Background(), echoRequest{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (echoRequest{"hello"}); want != have {
+		t.Errorf("want %#v, have %#v", want, have)
+	}
+	if want, have := "application/json; charset=utf-8", <-contentTypes; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerDecodeErrorNotWrappedTwice(t *testing.T) {
+	errs := make(chan error, 1)
+	handler := httptransport.NewServer(
+		context.Background(),
+		func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+		httptransport.DecodeJSONRequest(echoRequest{}),
+		httptransport.EncodeJSONResponse,
+		httptransport.ServerErrorEncoder(func(_ context.Context, err error, w http.ResponseWriter) { errs <- err }),
+	)
+	req, _ := http.NewRequest("POST", "/", strings.NewReader(`not json`))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	e := (<-errs).(httptransport.Error)
+	if _, ok := e.Err.(httptransport.Error); ok {
+		t.Errorf("decode error wrapped twice: %v", e)
+	}
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+
+	"golang.org/x/net/context"
 )
 
 const (
@@ -16,6 +20,10 @@ const (
 
 	// DomainDecode is an error during request or response decoding.
 	DomainDecode = "Decode"
+
+	// DomainDisconnect is an error caused by the client going away before
+	// the server finished processing the request.
+	DomainDisconnect = "Disconnect"
 )
 
 // Error is an error that occurred at some phase within the transport.
@@ -31,3 +39,75 @@ type Error struct {
 func (e Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.Domain, e.Err)
 }
+
+// StatusCoder is checked by the default error encoder and by EncodeJSONError.
+// If an error value implements StatusCoder, its StatusCode is used as the
+// response status code, instead of one derived from the error's Domain.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Headerer is checked by the default error encoder and by EncodeJSONError. If
+// an error value implements Headerer, the provided headers are added to the
+// response, after the Content-Type has been set.
+type Headerer interface {
+	Headers() http.Header
+}
+
+// EncodeJSONError is an ErrorEncoder that writes errors as JSON. If the error
+// implements json.Marshaler and marshals successfully, that is used as the
+// response body; otherwise the body is {"error": "<message>"}. StatusCoder and
+// Headerer are honoured. Errors wrapped in an Error are unwrapped first, so
+// only the concrete error is inspected and reported to the client.
+func EncodeJSONError(_ context.Context, err error, w http.ResponseWriter) {
+	cause := errorCause(err)
+	body := marshalJSONError(cause)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	addErrorHeaders(w, cause)
+	w.WriteHeader(errorStatusCode(err))
+	w.Write(body)
+}
+
+func marshalJSONError(err error) []byte {
+	if m, ok := err.(json.Marshaler); ok {
+		if body, merr := m.MarshalJSON(); merr == nil {
+			return body
+		}
+	}
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return body
+}
+
+// errorCause returns the concrete error wrapped by an Error, or err itself.
+func errorCause(err error) error {
+	if e, ok := err.(Error); ok && e.Err != nil {
+		return e.Err
+	}
+	return err
+}
+
+// errorStatusCode returns the status code err should be reported with.
+func errorStatusCode(err error) int {
+	if sc, ok := errorCause(err).(StatusCoder); ok {
+		return sc.StatusCode()
+	}
+	if e, ok := err.(Error); ok {
+		switch e.Domain {
+		case DomainDecode:
+			return http.StatusBadRequest
+		case DomainDo:
+			return http.StatusServiceUnavailable // too aggressive?
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+func addErrorHeaders(w http.ResponseWriter, err error) {
+	if h, ok := err.(Headerer); ok {
+		for k, vs := range h.Headers() {
+			for _, v := range vs {
+				w.Header().Add(k, v)
+			}
+		}
+	}
+}
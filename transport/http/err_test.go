@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -47,6 +49,79 @@ func TestClientEndpointEncodeError(t *testing.T) {
 	}
 }
 
+type conflictError struct{ id string }
+
+func (e conflictError) Error() string { return "conflict on " + e.id }
+
+func (e conflictError) StatusCode() int { return http.StatusConflict }
+
+func (e conflictError) Headers() http.Header {
+	return http.Header{"X-Conflict-Id": []string{e.id}}
+}
+
+func (e conflictError) MarshalJSON() ([]byte, error) {
+	return []byte(`{"conflict":"` + e.id + `"}`), nil
+}
+
+func TestEncodeJSONError(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		code   int
+		header string
+		body   string
+	}{
+		{
+			err:  errors.New("dang"),
+			code: http.StatusInternalServerError,
+			body: `{"error":"dang"}`,
+		},
+		{
+			err:  httptransport.Error{Domain: httptransport.DomainDecode, Err: errors.New("dang")},
+			code: http.StatusBadRequest,
+			body: `{"error":"dang"}`,
+		},
+		{
+			err:    httptransport.Error{Domain: httptransport.DomainDo, Err: conflictError{"abc"}},
+			code:   http.StatusConflict,
+			header: "abc",
+			body:   `{"conflict":"abc"}`,
+		},
+	} {
+		rec := httptest.NewRecorder()
+		httptransport.EncodeJSONError(context.Background(), tc.err, rec)
+		if want, have := tc.code, rec.Code; want != have {
+			t.Errorf("%v: code: want %d, have %d", tc.err, want, have)
+		}
+		if want, have := "application/json; charset=utf-8", rec.Header().Get("Content-Type"); want != have {
+			t.Errorf("%v: Content-Type: want %q, have %q", tc.err, want, have)
+		}
+		if want, have := tc.header, rec.Header().Get("X-Conflict-Id"); want != have {
+			t.Errorf("%v: X-Conflict-Id: want %q, have %q", tc.err, want, have)
+		}
+		if want, have := tc.body, strings.TrimSpace(rec.Body.String()); want != have {
+			t.Errorf("%v: body: want %s, have %s", tc.err, want, have)
+		}
+	}
+}
+
+func TestDefaultErrorEncoderStatusCoder(t *testing.T) {
+	handler := httptransport.NewServer(
+		context.Background(),
+		func(context.Context, interface{}) (interface{}, error) { return nil, conflictError{"abc"} },
+		func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+		func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if want, have := http.StatusConflict, rec.Code; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := "abc", rec.Header().Get("X-Conflict-Id"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
 func ExampleErrOutput() {
 	sampleErr := errors.New("oh no, an error")
 	err := httptransport.Error{Domain: httptransport.DomainDo, Err: sampleErr}
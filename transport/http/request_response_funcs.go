@@ -17,6 +17,11 @@ type RequestFunc func(context.Context, *http.Request) context.Context
 // after invoking the endpoint but prior to writing a response.
 type ResponseFunc func(context.Context, http.ResponseWriter)
 
+// ClientResponseFunc may take information from an HTTP response and put it into
+// a request context. ClientResponseFuncs are only executed in clients, after a
+// response has been received but prior to it being decoded.
+type ClientResponseFunc func(context.Context, *http.Response) context.Context
+
 // SetContentType returns a ResponseFunc that sets the Content-Type header to
 // the provided value.
 func SetContentType(contentType string) ResponseFunc {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -19,6 +20,8 @@ type Server struct {
 	after        []ResponseFunc
 	errorEncoder ErrorEncoder
 	logger       log.Logger
+	joinReqCtx   bool
+	finalizer    ServerFinalizerFunc
 }
 
 // NewServer constructs a new server, which implements http.Server and wraps
@@ -63,7 +66,8 @@ func ServerAfter(after ...ResponseFunc) ServerOption {
 // whenever they're encountered in the processing of a request. Clients can
 // use this to provide custom error formatting and response codes. By default,
 // errors will be written as plain text with an appropriate, if generic,
-// status code.
+// status code, unless they implement StatusCoder. See EncodeJSONError for an
+// encoder that writes errors as JSON.
 func ServerErrorEncoder(ee ErrorEncoder) ServerOption {
 	return func(s *Server) { s.errorEncoder = ee }
 }
@@ -74,40 +78,99 @@ func ServerErrorLogger(logger log.Logger) ServerOption {
 	return func(s *Server) { s.logger = logger }
 }
 
+// ServerJoinRequestContext makes the server derive each request context from
+// both its base context and the context of the incoming HTTP request. Values
+// from either are visible to the endpoint, and the request context is canceled
+// as soon as either of them is done, e.g. when the client disconnects. Errors
+// that occur after the client has gone away are reported with DomainDisconnect.
+// By default, only the base context is used.
+func ServerJoinRequestContext(join bool) ServerOption {
+	return func(s *Server) { s.joinReqCtx = join }
+}
+
+// ServerFinalizer is executed at the end of every HTTP request, on both the
+// success and the error path. By default, no finalizer is registered.
+func ServerFinalizer(f ServerFinalizerFunc) ServerOption {
+	return func(s *Server) { s.finalizer = f }
+}
+
 // ServeHTTP implements http.Handler.
 func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithCancel(s.ctx)
+	ctx, cancel := s.requestContext(r)
 	defer cancel()
 
+	var failure Error
+	if s.finalizer != nil {
+		iw := &interceptingWriter{ResponseWriter: w, code: http.StatusOK}
+		defer func(begin time.Time) {
+			s.finalizer(ctx, iw.code, iw.written, time.Since(begin), failure.Domain)
+		}(time.Now())
+		w = iw.reqWriter()
+	}
+
 	for _, f := range s.before {
 		ctx = f(ctx, r)
 	}
 
 	request, err := s.dec(ctx, r)
 	if err != nil {
+		failure = s.domainError(r, DomainDecode, err)
 		s.logger.Log("err", err)
-		s.errorEncoder(ctx, Error{Domain: DomainDecode, Err: err}, w)
+		s.errorEncoder(ctx, failure, w)
 		return
 	}
 
 	response, err := s.e(ctx, request)
 	if err != nil {
+		failure = s.domainError(r, DomainDo, err)
 		s.logger.Log("err", err)
-		s.errorEncoder(ctx, Error{Domain: DomainDo, Err: err}, w)
+		s.errorEncoder(ctx, failure, w)
 		return
 	}
 
+	if s.joinReqCtx {
+		if err := r.Context().Err(); err != nil {
+			failure = Error{Domain: DomainDisconnect, Err: err}
+			s.logger.Log("err", err)
+			s.errorEncoder(ctx, failure, w)
+			return
+		}
+	}
+
 	for _, f := range s.after {
 		f(ctx, w)
 	}
 
 	if err := s.enc(ctx, w, response); err != nil {
+		failure = s.domainError(r, DomainEncode, err)
 		s.logger.Log("err", err)
-		s.errorEncoder(ctx, Error{Domain: DomainEncode, Err: err}, w)
+		s.errorEncoder(ctx, failure, w)
 		return
 	}
 }
 
+// requestContext returns the context for serving r.
+func (s Server) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.joinReqCtx {
+		return joinContexts(s.ctx, r.Context())
+	}
+	return context.WithCancel(s.ctx)
+}
+
+// domainError wraps err in an Error of the given domain. Errors that already
+// are an Error of that domain are not wrapped again. If the request context is
+// joined and the client has already gone away, DomainDisconnect is used
+// instead, so that disconnects can be told apart from genuine failures.
+func (s Server) domainError(r *http.Request, domain string, err error) Error {
+	if e, ok := err.(Error); ok && e.Domain == domain {
+		err = e.Err
+	}
+	if s.joinReqCtx && r.Context().Err() != nil {
+		domain = DomainDisconnect
+	}
+	return Error{Domain: domain, Err: err}
+}
+
 // ErrorEncoder is responsible for encoding an error to the ResponseWriter.
 //
 // In the server implementation, only kit/transport/http.Error values are ever
@@ -118,17 +181,87 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 type ErrorEncoder func(ctx context.Context, err error, w http.ResponseWriter)
 
 func defaultErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
-	switch e := err.(type) {
-	case Error:
-		switch e.Domain {
-		case DomainDecode:
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		case DomainDo:
-			http.Error(w, err.Error(), http.StatusServiceUnavailable) // too aggressive?
-		default:
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-	default:
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	addErrorHeaders(w, errorCause(err))
+	http.Error(w, err.Error(), errorStatusCode(err))
+}
+
+// ServerFinalizerFunc can be used to perform work at the end of an HTTP
+// request, after the response has been written to the client. It receives the
+// status code and the number of body bytes written, how long the request took
+// to serve, and the domain of the error that ended it, or the empty string if
+// the request succeeded. The principal intended uses are access logging and
+// request metrics.
+type ServerFinalizerFunc func(ctx context.Context, code int, size int64, took time.Duration, domain string)
+
+// interceptingWriter records the status code and body size of a response.
+type interceptingWriter struct {
+	http.ResponseWriter
+	code    int
+	written int64
+}
+
+// WriteHeader may not be explicitly called, so care must be taken to
+// initialize w.code to its default value of http.StatusOK.
+func (w *interceptingWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *interceptingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// reqWriter returns w, extended with the optional interfaces of http.Flusher,
+// http.Hijacker and http.CloseNotifier that the wrapped ResponseWriter
+// implements, so that streaming and connection upgrades keep working when a
+// finalizer is set.
+func (w *interceptingWriter) reqWriter() http.ResponseWriter {
+	f, isFlusher := w.ResponseWriter.(http.Flusher)
+	h, isHijacker := w.ResponseWriter.(http.Hijacker)
+	c, isCloseNotifier := w.ResponseWriter.(http.CloseNotifier)
+	switch {
+	case isFlusher && isHijacker && isCloseNotifier:
+		return struct {
+			*interceptingWriter
+			http.Flusher
+			http.Hijacker
+			http.CloseNotifier
+		}{w, f, h, c}
+	case isFlusher && isHijacker:
+		return struct {
+			*interceptingWriter
+			http.Flusher
+			http.Hijacker
+		}{w, f, h}
+	case isFlusher && isCloseNotifier:
+		return struct {
+			*interceptingWriter
+			http.Flusher
+			http.CloseNotifier
+		}{w, f, c}
+	case isHijacker && isCloseNotifier:
+		return struct {
+			*interceptingWriter
+			http.Hijacker
+			http.CloseNotifier
+		}{w, h, c}
+	case isFlusher:
+		return struct {
+			*interceptingWriter
+			http.Flusher
+		}{w, f}
+	case isHijacker:
+		return struct {
+			*interceptingWriter
+			http.Hijacker
+		}{w, h}
+	case isCloseNotifier:
+		return struct {
+			*interceptingWriter
+			http.CloseNotifier
+		}{w, c}
 	}
+	return w
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -80,6 +81,192 @@ func TestServerErrorEncoder(t *testing.T) {
 	}
 }
 
+func TestServerJoinRequestContext(t *testing.T) {
+	type key int
+	const (
+		baseKey key = iota
+		reqKey
+	)
+	var (
+		base    = context.WithValue(context.Background(), baseKey, "base")
+		values  = make(chan [2]interface{}, 1)
+		handler = httptransport.NewServer(
+			base,
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				values <- [2]interface{}{ctx.Value(baseKey), ctx.Value(reqKey)}
+				return struct{}{}, nil
+			},
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+			httptransport.ServerJoinRequestContext(true),
+		)
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), reqKey, "req"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if want, have := http.StatusOK, rec.Code; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	have := <-values
+	if want := "base"; want != have[0] {
+		t.Errorf("base value: want %q, have %v", want, have[0])
+	}
+	if want := "req"; want != have[1] {
+		t.Errorf("request value: want %q, have %v", want, have[1])
+	}
+}
+
+func TestServerJoinRequestContextDisconnect(t *testing.T) {
+	var (
+		reqCtx, disconnect = context.WithCancel(context.Background())
+		domains            = make(chan string, 1)
+		handler            = httptransport.NewServer(
+			context.Background(),
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				disconnect()
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+			httptransport.ServerJoinRequestContext(true),
+			httptransport.ServerErrorEncoder(func(_ context.Context, err error, w http.ResponseWriter) {
+				domains <- err.(httptransport.Error).Domain
+			}),
+		)
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(reqCtx))
+	if want, have := httptransport.DomainDisconnect, <-domains; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerBaseContextCancel(t *testing.T) {
+	var (
+		base, cancel = context.WithCancel(context.Background())
+		domains      = make(chan string, 1)
+		handler      = httptransport.NewServer(
+			base,
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				cancel()
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+			httptransport.ServerJoinRequestContext(true),
+			httptransport.ServerErrorEncoder(func(_ context.Context, err error, w http.ResponseWriter) {
+				domains <- err.(httptransport.Error).Domain
+			}),
+		)
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if want, have := httptransport.DomainDo, <-domains; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerFinalizer(t *testing.T) {
+	type result struct {
+		code   int
+		size   int64
+		domain string
+	}
+	const body = "hello, world"
+	var (
+		results   = make(chan result, 1)
+		finalizer = func(_ context.Context, code int, size int64, took time.Duration, domain string) {
+			if took < 0 {
+				t.Errorf("negative duration %v", took)
+			}
+			results <- result{code, size, domain}
+		}
+		handler = httptransport.NewServer(
+			context.Background(),
+			func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(_ context.Context, w http.ResponseWriter, _ interface{}) error {
+				w.WriteHeader(http.StatusCreated)
+				_, err := w.Write([]byte(body))
+				return err
+			},
+			httptransport.ServerFinalizer(finalizer),
+		)
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if want, have := (result{http.StatusCreated, int64(len(body)), ""}), <-results; want != have {
+		t.Errorf("want %+v, have %+v", want, have)
+	}
+}
+
+func TestServerFinalizerError(t *testing.T) {
+	var (
+		codes     = make(chan int, 1)
+		domains   = make(chan string, 1)
+		finalizer = func(_ context.Context, code int, _ int64, _ time.Duration, domain string) {
+			codes <- code
+			domains <- domain
+		}
+		handler = httptransport.NewServer(
+			context.Background(),
+			func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, errors.New("dang") },
+			func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+			httptransport.ServerFinalizer(finalizer),
+		)
+	)
+	req, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if want, have := http.StatusBadRequest, <-codes; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := httptransport.DomainDecode, <-domains; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerFinalizerOptionalInterfaces(t *testing.T) {
+	type result struct{ flusher, hijacker, closeNotifier bool }
+	var (
+		results = make(chan result, 1)
+		handler = httptransport.NewServer(
+			context.Background(),
+			func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(_ context.Context, w http.ResponseWriter, _ interface{}) error {
+				_, flusher := w.(http.Flusher)
+				_, hijacker := w.(http.Hijacker)
+				_, closeNotifier := w.(http.CloseNotifier)
+				results <- result{flusher, hijacker, closeNotifier}
+				return nil
+			},
+			httptransport.ServerFinalizer(func(context.Context, int, int64, time.Duration, string) {}),
+		)
+	)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if want, have := (result{true, true, true}), <-results; want != have {
+		t.Errorf("want %+v, have %+v", want, have)
+	}
+
+	// Interfaces the underlying writer lacks aren't claimed either.
+	req, _ := http.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if want, have := (result{true, false, false}), <-results; want != have {
+		t.Errorf("want %+v, have %+v", want, have)
+	}
+}
+
 func TestServerHappyPath(t *testing.T) {
 	_, step, response := testServer(t)
 	step()
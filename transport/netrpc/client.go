@@ -0,0 +1,105 @@
+package netrpc
+
+import (
+	"fmt"
+	"net/rpc"
+	"reflect"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// Client wraps a net/rpc client and provides a method that implements
+// endpoint.Endpoint.
+type Client struct {
+	client        *rpc.Client
+	serviceMethod string
+	enc           EncodeRequestFunc
+	dec           DecodeResponseFunc
+	reply         reflect.Type
+	before        []RequestFunc
+	after         []ResponseFunc
+}
+
+// NewClient constructs a usable Client for a single remote method, named as
+// "Service.Method" like in rpc.Client.Call. Pass a zero value of the net/rpc
+// reply type as the reply argument; the DecodeResponseFunc receives a pointer
+// to a new value of that type.
+func NewClient(
+	client *rpc.Client,
+	serviceMethod string,
+	enc EncodeRequestFunc,
+	dec DecodeResponseFunc,
+	reply interface{},
+	options ...ClientOption,
+) *Client {
+	c := &Client{
+		client:        client,
+		serviceMethod: serviceMethod,
+		enc:           enc,
+		dec:           dec,
+		reply:         reflect.TypeOf(reflect.Indirect(reflect.ValueOf(reply)).Interface()),
+		before:        []RequestFunc{},
+		after:         []ResponseFunc{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ClientOption sets an optional parameter for clients.
+type ClientOption func(*Client)
+
+// SetClientBefore sets the RequestFuncs that are applied to the outgoing net/rpc
+// request argument before the call is made.
+func SetClientBefore(before ...RequestFunc) ClientOption {
+	return func(c *Client) { c.before = before }
+}
+
+// ClientAfter sets the ResponseFuncs that are applied to the incoming net/rpc
+// reply prior to it being decoded.
+func ClientAfter(after ...ResponseFunc) ClientOption {
+	return func(c *Client) { c.after = after }
+}
+
+// Endpoint returns a usable endpoint that will invoke the net/rpc method
+// specified by the client. If the context is canceled before the call
+// returns, the endpoint returns the context's error without waiting.
+func (c Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		req, err := c.enc(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("Encode: %v", err)
+		}
+
+		for _, f := range c.before {
+			ctx = f(ctx, req)
+		}
+
+		reply := reflect.New(c.reply).Interface()
+		call := c.client.Go(c.serviceMethod, req, reply, make(chan *rpc.Call, 1))
+		select {
+		case <-call.Done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.Error != nil {
+			return nil, call.Error
+		}
+
+		for _, f := range c.after {
+			ctx = f(ctx, reply)
+		}
+
+		response, err := c.dec(ctx, reply)
+		if err != nil {
+			return nil, fmt.Errorf("Decode: %v", err)
+		}
+		return response, nil
+	}
+}
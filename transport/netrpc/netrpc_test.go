@@ -0,0 +1,165 @@
+package netrpc_test
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	netrpctransport "github.com/go-kit/kit/transport/netrpc"
+)
+
+type UpperRequest struct{ S string }
+
+type UpperReply struct{ V string }
+
+// binding is a net/rpc receiver that delegates to a Go kit handler.
+type binding struct {
+	upper netrpctransport.Handler
+}
+
+func (b binding) Upper(request UpperRequest, reply *UpperReply) error {
+	resp, err := b.upper.ServeNetRPC(request)
+	if err != nil {
+		return err
+	}
+	*reply = resp.(UpperReply)
+	return nil
+}
+
+func newTestClient(t *testing.T, h netrpctransport.Handler) *rpc.Client {
+	s := rpc.NewServer()
+	if err := s.RegisterName("test", binding{h}); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	go s.ServeConn(serverConn)
+	return rpc.NewClient(clientConn)
+}
+
+func TestRoundTrip(t *testing.T) {
+	type key int
+	var (
+		decodes = make(chan interface{}, 1)
+		server  = netrpctransport.NewServer(
+			context.Background(),
+			func(_ context.Context, request interface{}) (interface{}, error) {
+				return "HELLO", nil
+			},
+			func(_ context.Context, r interface{}) (interface{}, error) { return r.(UpperRequest).S, nil },
+			func(_ context.Context, response interface{}) (interface{}, error) {
+				return UpperReply{response.(string)}, nil
+			},
+		)
+		client = netrpctransport.NewClient(
+			newTestClient(t, server),
+			"test.Upper",
+			func(_ context.Context, request interface{}) (interface{}, error) {
+				return UpperRequest{request.(string)}, nil
+			},
+			func(ctx context.Context, reply interface{}) (interface{}, error) {
+				decodes <- ctx.Value(key(0))
+				return reply.(*UpperReply).V, nil
+			},
+			UpperReply{},
+			netrpctransport.ClientAfter(func(ctx context.Context, reply interface{}) context.Context {
+				return context.WithValue(ctx, key(0), reply.(*UpperReply).V)
+			}),
+		)
+	)
+
+	response, err := client.Endpoint()(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "HELLO", response; want != have {
+		t.Errorf("want %q, have %v", want, have)
+	}
+	if want, have := "HELLO", <-decodes; want != have {
+		t.Errorf("ClientAfter: want %q, have %v", want, have)
+	}
+}
+
+func TestServerAfter(t *testing.T) {
+	type key int
+	server := netrpctransport.NewServer(
+		context.Background(),
+		func(context.Context, interface{}) (interface{}, error) { return "HELLO", nil },
+		func(_ context.Context, r interface{}) (interface{}, error) { return r, nil },
+		func(ctx context.Context, response interface{}) (interface{}, error) {
+			return UpperReply{ctx.Value(key(0)).(string) + response.(string)}, nil
+		},
+		netrpctransport.ServerAfter(func(ctx context.Context, response interface{}) context.Context {
+			return context.WithValue(ctx, key(0), "after:")
+		}),
+	)
+	reply, err := server.ServeNetRPC(UpperRequest{"hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := (UpperReply{"after:HELLO"}), reply; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestServerBadDecode(t *testing.T) {
+	server := netrpctransport.NewServer(
+		context.Background(),
+		func(context.Context, interface{}) (interface{}, error) { return UpperReply{}, nil },
+		func(context.Context, interface{}) (interface{}, error) { return nil, errors.New("dang") },
+		func(_ context.Context, response interface{}) (interface{}, error) { return response, nil },
+	)
+	_, err := server.ServeNetRPC(UpperRequest{})
+	if _, ok := err.(netrpctransport.BadRequestError); !ok {
+		t.Errorf("want BadRequestError, have %T (%v)", err, err)
+	}
+}
+
+func TestClientRemoteError(t *testing.T) {
+	var (
+		server = netrpctransport.NewServer(
+			context.Background(),
+			func(context.Context, interface{}) (interface{}, error) { return nil, errors.New("dang") },
+			func(_ context.Context, r interface{}) (interface{}, error) { return r, nil },
+			func(_ context.Context, response interface{}) (interface{}, error) { return response, nil },
+		)
+		client = netrpctransport.NewClient(
+			newTestClient(t, server),
+			"test.Upper",
+			func(_ context.Context, request interface{}) (interface{}, error) { return UpperRequest{}, nil },
+			func(_ context.Context, reply interface{}) (interface{}, error) { return reply, nil },
+			UpperReply{},
+		)
+	)
+	_, err := client.Endpoint()(context.Background(), "hello")
+	if want, have := rpc.ServerError("dang"), err; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	var (
+		block  = make(chan struct{})
+		server = netrpctransport.NewServer(
+			context.Background(),
+			func(context.Context, interface{}) (interface{}, error) { <-block; return UpperReply{}, nil },
+			func(_ context.Context, r interface{}) (interface{}, error) { return r, nil },
+			func(_ context.Context, response interface{}) (interface{}, error) { return response, nil },
+		)
+		client = netrpctransport.NewClient(
+			newTestClient(t, server),
+			"test.Upper",
+			func(_ context.Context, request interface{}) (interface{}, error) { return UpperRequest{}, nil },
+			func(_ context.Context, reply interface{}) (interface{}, error) { return reply, nil },
+			UpperReply{},
+		)
+	)
+	defer close(block)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if want, have := context.Canceled, func() error { _, err := client.Endpoint()(ctx, "hello"); return err }(); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,18 @@
+package netrpc
+
+import "golang.org/x/net/context"
+
+// RequestFunc may take information from a net/rpc request argument and put it
+// into a request context. net/rpc has no notion of headers, so anything
+// propagated this way must be part of the argument itself. In Servers,
+// RequestFuncs are executed prior to decoding the request. In Clients,
+// RequestFuncs are executed after encoding the request but prior to making
+// the call.
+type RequestFunc func(ctx context.Context, request interface{}) context.Context
+
+// ResponseFunc may take information from a response and put it into a
+// request context. In Servers, ResponseFuncs are executed on the endpoint
+// response after the endpoint is invoked, but before the response is encoded.
+// In Clients, ResponseFuncs are executed on the net/rpc reply after the call
+// has returned, but prior to decoding the reply.
+type ResponseFunc func(ctx context.Context, response interface{}) context.Context
@@ -0,0 +1,113 @@
+package netrpc
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+)
+
+// Handler which should be called from the net/rpc binding of the service
+// implementation. net/rpc methods don't carry a context, so the Server's
+// base context is used for every request.
+type Handler interface {
+	ServeNetRPC(request interface{}) (response interface{}, err error)
+}
+
+// Server wraps an endpoint and implements netrpc.Handler.
+type Server struct {
+	ctx    context.Context
+	e      endpoint.Endpoint
+	dec    DecodeRequestFunc
+	enc    EncodeResponseFunc
+	before []RequestFunc
+	after  []ResponseFunc
+	logger log.Logger
+}
+
+// NewServer constructs a new server, which implements netrpc.Handler and
+// wraps the provided endpoint.
+func NewServer(
+	ctx context.Context,
+	e endpoint.Endpoint,
+	dec DecodeRequestFunc,
+	enc EncodeResponseFunc,
+	options ...ServerOption,
+) *Server {
+	s := &Server{
+		ctx:    ctx,
+		e:      e,
+		dec:    dec,
+		enc:    enc,
+		logger: log.NewNopLogger(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// ServerOption sets an optional parameter for servers.
+type ServerOption func(*Server)
+
+// ServerBefore functions are executed on the net/rpc request argument before
+// the request is decoded.
+func ServerBefore(before ...RequestFunc) ServerOption {
+	return func(s *Server) { s.before = before }
+}
+
+// ServerAfter functions are executed on the endpoint response after the
+// endpoint is invoked, but before the response is encoded.
+func ServerAfter(after ...ResponseFunc) ServerOption {
+	return func(s *Server) { s.after = after }
+}
+
+// ServerErrorLogger is used to log non-terminal errors. By default, no errors
+// are logged.
+func ServerErrorLogger(logger log.Logger) ServerOption {
+	return func(s *Server) { s.logger = logger }
+}
+
+// ServeNetRPC implements netrpc.Handler.
+func (s Server) ServeNetRPC(r interface{}) (interface{}, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	request, err := s.dec(ctx, r)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, BadRequestError{err}
+	}
+
+	response, err := s.e(ctx, request)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, err
+	}
+
+	for _, f := range s.after {
+		ctx = f(ctx, response)
+	}
+
+	reply, err := s.enc(ctx, response)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// BadRequestError is an error in decoding the request.
+type BadRequestError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (err BadRequestError) Error() string {
+	return err.Err.Error()
+}
@@ -0,0 +1,130 @@
+package thrift
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+// CallFunc invokes a single method of a generated Thrift client, taking the
+// encoded call arguments and returning the method's result. For example:
+//
+//	func(_ context.Context, request interface{}) (interface{}, error) {
+//	    args := request.(*add.AddServiceSumArgs)
+//	    return client.Sum(args.A, args.B)
+//	}
+type CallFunc func(ctx context.Context, request interface{}) (response interface{}, err error)
+
+// Client wraps a method of a generated Thrift client and provides a method
+// that implements endpoint.Endpoint.
+type Client struct {
+	call        CallFunc
+	enc         EncodeRequestFunc
+	dec         DecodeResponseFunc
+	before      []RequestFunc
+	errorMapper ErrorMapper
+	mtx         *sync.Mutex
+}
+
+// NewClient constructs a usable Client for a single remote method.
+//
+// Generated Thrift clients are not safe for concurrent use, so calls are
+// serialized. Clients of different methods that share the same generated
+// client must share the same mutex; see ClientMutex.
+func NewClient(
+	call CallFunc,
+	enc EncodeRequestFunc,
+	dec DecodeResponseFunc,
+	options ...ClientOption,
+) *Client {
+	c := &Client{
+		call:        call,
+		enc:         enc,
+		dec:         dec,
+		before:      []RequestFunc{},
+		errorMapper: nopErrorMapper,
+		mtx:         &sync.Mutex{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ClientOption sets an optional parameter for clients.
+type ClientOption func(*Client)
+
+// SetClientBefore sets the RequestFuncs that are applied to the outgoing
+// Thrift call arguments before the call is made.
+func SetClientBefore(before ...RequestFunc) ClientOption {
+	return func(c *Client) { c.before = before }
+}
+
+// ClientErrorMapper is used to translate errors returned by the Thrift call,
+// e.g. exceptions declared in the IDL, into the errors returned from the
+// endpoint. By default, errors are returned unchanged.
+func ClientErrorMapper(m ErrorMapper) ClientOption {
+	return func(c *Client) { c.errorMapper = m }
+}
+
+// ClientMutex sets the mutex used to serialize calls. Clients that share an
+// underlying generated Thrift client should share a mutex, too.
+func ClientMutex(mtx *sync.Mutex) ClientOption {
+	return func(c *Client) { c.mtx = mtx }
+}
+
+// Endpoint returns a usable endpoint that will invoke the Thrift method
+// specified by the client. Thrift calls can't be interrupted, so if the
+// context is canceled before the call returns, the endpoint returns the
+// context's error and leaves the call to finish in the background. Calls that
+// are canceled while waiting for another call to finish are never made.
+func (c Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		req, err := c.enc(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("Encode: %v", err)
+		}
+
+		for _, f := range c.before {
+			ctx = f(ctx, req)
+		}
+
+		type result struct {
+			reply interface{}
+			err   error
+		}
+		done := make(chan result, 1)
+		go func() {
+			c.mtx.Lock()
+			defer c.mtx.Unlock()
+			if err := ctx.Err(); err != nil {
+				done <- result{nil, err} // canceled while waiting; don't call
+				return
+			}
+			reply, err := c.call(ctx, req)
+			done <- result{reply, err}
+		}()
+
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if res.err != nil {
+			return nil, c.errorMapper(ctx, res.err)
+		}
+
+		response, err := c.dec(ctx, res.reply)
+		if err != nil {
+			return nil, fmt.Errorf("Decode: %v", err)
+		}
+		return response, nil
+	}
+}
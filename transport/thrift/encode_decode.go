@@ -0,0 +1,29 @@
+package thrift
+
+import "golang.org/x/net/context"
+
+// DecodeRequestFunc extracts a user-domain request object from the arguments
+// of a Thrift method call. It's designed to be used in Thrift servers, for
+// server-side endpoints. One straightforward DecodeRequestFunc could be
+// something that converts from the generated Args struct to the concrete
+// request type.
+type DecodeRequestFunc func(context.Context, interface{}) (request interface{}, err error)
+
+// EncodeRequestFunc encodes the passed request object into the arguments of a
+// Thrift method call. It's designed to be used in Thrift clients, for
+// client-side endpoints. One straightforward EncodeRequestFunc could be
+// something that converts the object directly to the generated Args struct.
+type EncodeRequestFunc func(context.Context, interface{}) (request interface{}, err error)
+
+// EncodeResponseFunc encodes the passed response object to the result of a
+// Thrift method call. It's designed to be used in Thrift servers, for
+// server-side endpoints. One straightforward EncodeResponseFunc could be
+// something that converts the object directly to the generated reply struct.
+type EncodeResponseFunc func(context.Context, interface{}) (response interface{}, err error)
+
+// DecodeResponseFunc extracts a user-domain response object from the result
+// of a Thrift method call. It's designed to be used in Thrift clients, for
+// client-side endpoints. One straightforward DecodeResponseFunc could be
+// something that converts from the generated reply struct to the concrete
+// response type.
+type DecodeResponseFunc func(context.Context, interface{}) (response interface{}, err error)
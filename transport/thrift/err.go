@@ -0,0 +1,22 @@
+package thrift
+
+import "golang.org/x/net/context"
+
+// ErrorMapper translates errors at the boundary between Go kit and Thrift.
+// Servers use it to turn the errors of an endpoint into the exceptions
+// declared in the Thrift IDL, and clients use it to turn those exceptions back
+// into domain errors. Sharing a pair of ErrorMappers between both sides keeps
+// errors intact across the wire.
+type ErrorMapper func(ctx context.Context, err error) error
+
+// BadRequestError is an error in decoding the request.
+type BadRequestError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (err BadRequestError) Error() string {
+	return err.Err.Error()
+}
+
+func nopErrorMapper(_ context.Context, err error) error { return err }
@@ -0,0 +1,12 @@
+package thrift
+
+import "golang.org/x/net/context"
+
+// RequestFunc may take information from the arguments of a Thrift method call
+// and put it into a request context, or vice versa. Plain Thrift has no notion
+// of headers, so services that propagate headers usually carry them in a
+// field of the call arguments, e.g. a map<string,string>. In Servers,
+// RequestFuncs are executed prior to decoding the request. In Clients,
+// RequestFuncs are executed after encoding the request but prior to making
+// the call.
+type RequestFunc func(ctx context.Context, request interface{}) context.Context
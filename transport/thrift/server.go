@@ -0,0 +1,102 @@
+package thrift
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+)
+
+// Handler which should be called from the Thrift binding of the service
+// implementation, i.e. the handler passed to the generated TProcessor.
+// Generated Thrift handlers don't carry a context, so the Server's base
+// context is used for every request.
+type Handler interface {
+	ServeThrift(request interface{}) (response interface{}, err error)
+}
+
+// Server wraps an endpoint and implements thrift.Handler.
+type Server struct {
+	ctx         context.Context
+	e           endpoint.Endpoint
+	dec         DecodeRequestFunc
+	enc         EncodeResponseFunc
+	before      []RequestFunc
+	errorMapper ErrorMapper
+	logger      log.Logger
+}
+
+// NewServer constructs a new server, which implements thrift.Handler and
+// wraps the provided endpoint.
+func NewServer(
+	ctx context.Context,
+	e endpoint.Endpoint,
+	dec DecodeRequestFunc,
+	enc EncodeResponseFunc,
+	options ...ServerOption,
+) *Server {
+	s := &Server{
+		ctx:         ctx,
+		e:           e,
+		dec:         dec,
+		enc:         enc,
+		errorMapper: nopErrorMapper,
+		logger:      log.NewNopLogger(),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// ServerOption sets an optional parameter for servers.
+type ServerOption func(*Server)
+
+// ServerBefore functions are executed on the Thrift call arguments before the
+// request is decoded.
+func ServerBefore(before ...RequestFunc) ServerOption {
+	return func(s *Server) { s.before = before }
+}
+
+// ServerErrorMapper is used to translate errors encountered in the processing
+// of a request into the errors returned to the Thrift processor. Decoding
+// errors are passed as a BadRequestError. By default, errors are returned
+// unchanged.
+func ServerErrorMapper(m ErrorMapper) ServerOption {
+	return func(s *Server) { s.errorMapper = m }
+}
+
+// ServerErrorLogger is used to log non-terminal errors. By default, no errors
+// are logged.
+func ServerErrorLogger(logger log.Logger) ServerOption {
+	return func(s *Server) { s.logger = logger }
+}
+
+// ServeThrift implements thrift.Handler.
+func (s Server) ServeThrift(r interface{}) (interface{}, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	request, err := s.dec(ctx, r)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, s.errorMapper(ctx, BadRequestError{err})
+	}
+
+	response, err := s.e(ctx, request)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, s.errorMapper(ctx, err)
+	}
+
+	reply, err := s.enc(ctx, response)
+	if err != nil {
+		s.logger.Log("err", err)
+		return nil, s.errorMapper(ctx, err)
+	}
+	return reply, nil
+}
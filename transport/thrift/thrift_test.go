@@ -0,0 +1,170 @@
+package thrift_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	thrifttransport "github.com/go-kit/kit/transport/thrift"
+)
+
+// upperArgs stands in for a generated Thrift Args struct, which carries
+// headers in a map field.
+type upperArgs struct {
+	S       string
+	Headers map[string]string
+}
+
+type upperReply struct{ V string }
+
+var errUnknown = errors.New("unknown")
+
+// upperException stands in for an exception declared in the Thrift IDL.
+type upperException struct{ Reason string }
+
+func (e *upperException) Error() string { return e.Reason }
+
+func toException(_ context.Context, err error) error {
+	if err == errUnknown {
+		return &upperException{Reason: "unknown"}
+	}
+	return err
+}
+
+func fromException(_ context.Context, err error) error {
+	if e, ok := err.(*upperException); ok && e.Reason == "unknown" {
+		return errUnknown
+	}
+	return err
+}
+
+func TestRoundTrip(t *testing.T) {
+	type key int
+	var (
+		server = thrifttransport.NewServer(
+			context.Background(),
+			func(ctx context.Context, request interface{}) (interface{}, error) {
+				if want, have := "abc", ctx.Value(key(0)); want != have {
+					t.Errorf("header: want %q, have %v", want, have)
+				}
+				if request.(string) == "" {
+					return nil, errUnknown
+				}
+				return "HELLO", nil
+			},
+			func(_ context.Context, r interface{}) (interface{}, error) { return r.(*upperArgs).S, nil },
+			func(_ context.Context, response interface{}) (interface{}, error) {
+				return &upperReply{response.(string)}, nil
+			},
+			thrifttransport.ServerBefore(func(ctx context.Context, r interface{}) context.Context {
+				return context.WithValue(ctx, key(0), r.(*upperArgs).Headers["X-Request-Id"])
+			}),
+			thrifttransport.ServerErrorMapper(toException),
+		)
+		client = thrifttransport.NewClient(
+			func(_ context.Context, request interface{}) (interface{}, error) {
+				return server.ServeThrift(request) // stands in for the generated client
+			},
+			func(_ context.Context, request interface{}) (interface{}, error) {
+				return &upperArgs{S: request.(string), Headers: map[string]string{}}, nil
+			},
+			func(_ context.Context, reply interface{}) (interface{}, error) { return reply.(*upperReply).V, nil },
+			thrifttransport.SetClientBefore(func(ctx context.Context, r interface{}) context.Context {
+				r.(*upperArgs).Headers["X-Request-Id"] = "abc"
+				return ctx
+			}),
+			thrifttransport.ClientErrorMapper(fromException),
+		)
+	)
+
+	response, err := client.Endpoint()(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "HELLO", response; want != have {
+		t.Errorf("want %q, have %v", want, have)
+	}
+
+	if _, err := client.Endpoint()(context.Background(), ""); err != errUnknown {
+		t.Errorf("want %v, have %v", errUnknown, err)
+	}
+}
+
+func TestServerBadDecode(t *testing.T) {
+	server := thrifttransport.NewServer(
+		context.Background(),
+		func(context.Context, interface{}) (interface{}, error) { return &upperReply{}, nil },
+		func(context.Context, interface{}) (interface{}, error) { return nil, errors.New("dang") },
+		func(_ context.Context, response interface{}) (interface{}, error) { return response, nil },
+	)
+	_, err := server.ServeThrift(&upperArgs{})
+	if _, ok := err.(thrifttransport.BadRequestError); !ok {
+		t.Errorf("want BadRequestError, have %T (%v)", err, err)
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	client := thrifttransport.NewClient(
+		func(context.Context, interface{}) (interface{}, error) { <-block; return &upperReply{}, nil },
+		func(_ context.Context, request interface{}) (interface{}, error) { return &upperArgs{}, nil },
+		func(_ context.Context, reply interface{}) (interface{}, error) { return reply, nil },
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Endpoint()(ctx, "hello"); err != context.Canceled {
+		t.Errorf("want %v, have %v", context.Canceled, err)
+	}
+}
+
+func TestClientCanceledWhileWaiting(t *testing.T) {
+	var (
+		calls   = map[string]*int32{"first": new(int32), "second": new(int32)}
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	client := thrifttransport.NewClient(
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			s := request.(*upperArgs).S
+			atomic.AddInt32(calls[s], 1)
+			if s == "first" {
+				close(started)
+				<-release
+			}
+			return &upperReply{s}, nil
+		},
+		func(_ context.Context, request interface{}) (interface{}, error) {
+			return &upperArgs{S: request.(string)}, nil
+		},
+		func(_ context.Context, reply interface{}) (interface{}, error) { return reply.(*upperReply).V, nil },
+	)
+
+	firstc := make(chan error, 1)
+	go func() {
+		_, err := client.Endpoint()(context.Background(), "first")
+		firstc <- err
+	}()
+	<-started
+
+	// The second call waits behind the first, and is canceled meanwhile.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := client.Endpoint()(ctx, "second"); err != context.DeadlineExceeded {
+		t.Errorf("want %v, have %v", context.DeadlineExceeded, err)
+	}
+
+	close(release)
+	if err := <-firstc; err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the second call's goroutine time to take its turn.
+	time.Sleep(10 * time.Millisecond)
+	if want, have := int32(0), atomic.LoadInt32(calls["second"]); want != have {
+		t.Errorf("second call: want %d calls, have %d", want, have)
+	}
+}
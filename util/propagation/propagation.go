@@ -0,0 +1,193 @@
+// Package propagation carries request-scoped values, such as a tenant ID or
+// feature flags, across service hops. Servers extract the values from
+// allowlisted HTTP headers or gRPC metadata into the context, and clients
+// inject them back into outgoing requests.
+package propagation
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// Baggage is a set of request-scoped key-value pairs. Keys are header names
+// in lower case.
+type Baggage map[string]string
+
+type contextKey int
+
+const baggageContextKey contextKey = 0
+
+// NewContext returns a new context carrying the baggage. The baggage must not
+// be modified afterwards.
+func NewContext(ctx context.Context, b Baggage) context.Context {
+	return context.WithValue(ctx, baggageContextKey, b)
+}
+
+// FromContext returns the baggage carried by ctx, or nil if there is none.
+// The returned baggage must not be modified; use WithValue instead.
+func FromContext(ctx context.Context) Baggage {
+	b, _ := ctx.Value(baggageContextKey).(Baggage)
+	return b
+}
+
+// WithValue returns a new context whose baggage holds the key-value pair, in
+// addition to the baggage already carried by ctx.
+func WithValue(ctx context.Context, key, val string) context.Context {
+	parent := FromContext(ctx)
+	b := make(Baggage, len(parent)+1)
+	for k, v := range parent {
+		b[k] = v
+	}
+	b[strings.ToLower(key)] = val
+	return NewContext(ctx, b)
+}
+
+// Value returns the value associated with key in the baggage carried by ctx,
+// or the empty string if there is none.
+func Value(ctx context.Context, key string) string {
+	return FromContext(ctx)[strings.ToLower(key)]
+}
+
+// Propagator moves baggage between contexts and requests. Only allowlisted
+// keys are propagated, and values exceeding the size limits are dropped, so
+// that callers can't make a service forward arbitrary amounts of data.
+type Propagator struct {
+	allowed      map[string]bool
+	keys         []string
+	maxValueSize int
+	maxTotalSize int
+}
+
+// NewPropagator returns a Propagator for the given header names. Matching is
+// case-insensitive.
+func NewPropagator(keys []string, options ...PropagatorOption) *Propagator {
+	p := &Propagator{
+		allowed:      map[string]bool{},
+		maxValueSize: 256,
+		maxTotalSize: 4096,
+	}
+	for _, key := range keys {
+		key = strings.ToLower(key)
+		if !p.allowed[key] {
+			p.allowed[key] = true
+			p.keys = append(p.keys, key)
+		}
+	}
+	sort.Strings(p.keys)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// PropagatorOption sets an optional parameter for the Propagator.
+type PropagatorOption func(*Propagator)
+
+// MaxValueSize sets the maximum size in bytes of a single value. Larger
+// values are dropped. By default, values may be up to 256 bytes.
+func MaxValueSize(n int) PropagatorOption {
+	return func(p *Propagator) { p.maxValueSize = n }
+}
+
+// MaxTotalSize sets the maximum size in bytes of all keys and values taken
+// together. Once the limit is reached, further pairs are dropped, in key
+// order. By default, the total may be up to 4096 bytes.
+func MaxTotalSize(n int) PropagatorOption {
+	return func(p *Propagator) { p.maxTotalSize = n }
+}
+
+// FromHTTPRequest returns an http RequestFunc that extracts the allowlisted
+// headers of the request into the baggage of the returned context. Use it in
+// servers.
+func (p *Propagator) FromHTTPRequest() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return p.extract(ctx, r.Header.Get)
+	}
+}
+
+// ToHTTPRequest returns an http RequestFunc that sets the allowlisted
+// baggage found in ctx as headers of the request. Use it in clients.
+func (p *Propagator) ToHTTPRequest() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		p.inject(ctx, r.Header.Set)
+		return ctx
+	}
+}
+
+// FromGRPCRequest returns a grpc RequestFunc that extracts the allowlisted
+// metadata of the request into the baggage of the returned context. Binary
+// metadata, with a "-bin" key suffix, is decoded. Use it in servers.
+func (p *Propagator) FromGRPCRequest() grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		return p.extract(ctx, func(key string) string {
+			vals := (*md)[key]
+			if len(vals) == 0 {
+				return ""
+			}
+			_, val, err := grpctransport.DecodeKeyValue(key, vals[0])
+			if err != nil {
+				return ""
+			}
+			return val
+		})
+	}
+}
+
+// ToGRPCRequest returns a grpc RequestFunc that sets the allowlisted baggage
+// found in ctx as metadata of the request. Binary metadata, with a "-bin" key
+// suffix, is encoded. Use it in clients.
+func (p *Propagator) ToGRPCRequest() grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		p.inject(ctx, func(key, val string) {
+			key, val = grpctransport.EncodeKeyValue(key, val)
+			(*md)[key] = []string{val}
+		})
+		return ctx
+	}
+}
+
+// extract builds the baggage from the values returned by get, and returns a
+// context carrying it. Baggage already in ctx is kept, unless overridden.
+func (p *Propagator) extract(ctx context.Context, get func(key string) string) context.Context {
+	b := Baggage{}
+	for k, v := range FromContext(ctx) {
+		b[k] = v
+	}
+	p.each(get, func(key, val string) { b[key] = val })
+	if len(b) == 0 {
+		return ctx
+	}
+	return NewContext(ctx, b)
+}
+
+// inject passes the baggage found in ctx to set.
+func (p *Propagator) inject(ctx context.Context, set func(key, val string)) {
+	b := FromContext(ctx)
+	if len(b) == 0 {
+		return
+	}
+	p.each(func(key string) string { return b[key] }, set)
+}
+
+// each calls f with the allowlisted, non-empty values returned by get, in key
+// order, within the size limits.
+func (p *Propagator) each(get func(key string) string, f func(key, val string)) {
+	var total int
+	for _, key := range p.keys {
+		val := get(key)
+		if val == "" || len(val) > p.maxValueSize {
+			continue
+		}
+		if total += len(key) + len(val); total > p.maxTotalSize {
+			return
+		}
+		f(key, val)
+	}
+}
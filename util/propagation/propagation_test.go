@@ -0,0 +1,77 @@
+package propagation_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/go-kit/kit/util/propagation"
+)
+
+func TestHTTPRoundtrip(t *testing.T) {
+	p := propagation.NewPropagator([]string{"X-Tenant-ID", "X-Feature-Flags"})
+	ctx := propagation.WithValue(context.Background(), "X-Tenant-ID", "acme")
+	ctx = propagation.WithValue(ctx, "X-Secret", "hunter2")
+
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	p.ToHTTPRequest()(ctx, r)
+	if want, have := "acme", r.Header.Get("X-Tenant-ID"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "", r.Header.Get("X-Secret"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	r.Header.Set("X-Feature-Flags", "beta")
+	r.Header.Set("X-Secret", "hunter2")
+	ctx = p.FromHTTPRequest()(context.Background(), r)
+	for key, want := range map[string]string{
+		"x-tenant-id":     "acme",
+		"X-Feature-Flags": "beta",
+		"X-Secret":        "",
+	} {
+		if have := propagation.Value(ctx, key); want != have {
+			t.Errorf("%s: want %q, have %q", key, want, have)
+		}
+	}
+}
+
+func TestGRPCRoundtrip(t *testing.T) {
+	p := propagation.NewPropagator([]string{"tenant-id", "flags-bin"})
+	ctx := propagation.WithValue(context.Background(), "Tenant-ID", "acme")
+	ctx = propagation.WithValue(ctx, "flags-bin", "\x00\x01")
+
+	md := metadata.MD{}
+	p.ToGRPCRequest()(ctx, &md)
+	if want, have := "AAE=", md["flags-bin"]; len(have) != 1 || want != have[0] {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	ctx = p.FromGRPCRequest()(context.Background(), &md)
+	if want, have := "acme", propagation.Value(ctx, "tenant-id"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "\x00\x01", propagation.Value(ctx, "flags-bin"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	p := propagation.NewPropagator(
+		[]string{"a", "b", "c"},
+		propagation.MaxValueSize(4),
+		propagation.MaxTotalSize(8),
+	)
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	r.Header.Set("a", strings.Repeat("x", 5)) // value too large
+	r.Header.Set("b", "xxxx")                 // 5 bytes in total
+	r.Header.Set("c", "xxxx")                 // 10 bytes in total, over the limit
+
+	b := propagation.FromContext(p.FromHTTPRequest()(context.Background(), r))
+	if want, have := (propagation.Baggage{"b": "xxxx"}), b; len(want) != len(have) || want["b"] != have["b"] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,173 @@
+// Package requestid generates, accepts and forwards request IDs, so that log
+// lines emitted by different services on behalf of the same request can be
+// correlated.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/ctxlog"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	// HTTPHeader is the HTTP header carrying the request ID.
+	HTTPHeader = "X-Request-ID"
+
+	// GRPCKey is the gRPC metadata key carrying the request ID.
+	GRPCKey = "x-request-id"
+
+	// maxLength bounds the length of request IDs accepted from callers.
+	maxLength = 128
+)
+
+type contextKey int
+
+const requestIDContextKey contextKey = 0
+
+// NewContext returns a new context carrying the request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// FromContext returns the request ID carried by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey).(string)
+	return id, ok
+}
+
+// Valuer returns a log.Valuer that yields the request ID carried by ctx, or
+// the empty string if there is none. Bind it to a log.Context to include the
+// request ID in every log line.
+//
+//	logger := log.NewContext(logger).With("request_id", requestid.Valuer(ctx))
+func Valuer(ctx context.Context) log.Valuer {
+	return func() interface{} {
+		id, _ := FromContext(ctx)
+		return id
+	}
+}
+
+// LogExtractor returns a ctxlog.Extractor that yields the request ID carried
+// by the context under the given key.
+func LogExtractor(key string) ctxlog.Extractor {
+	return func(ctx context.Context) []interface{} {
+		if id, ok := FromContext(ctx); ok {
+			return []interface{}{key, id}
+		}
+		return nil
+	}
+}
+
+// Generator returns a new request ID.
+type Generator func() string
+
+// NewID is the default Generator. It returns 16 random bytes, hex-encoded.
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// FromHTTPRequest returns an http RequestFunc that puts the request ID found
+// in the request headers into the returned context. If the request has no
+// valid request ID, a new one is generated. A nil generator means NewID.
+// Use it in servers.
+func FromHTTPRequest(gen Generator) httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return NewContext(ctx, acceptOrGenerate(r.Header.Get(HTTPHeader), gen))
+	}
+}
+
+// ToHTTPResponse returns an http ResponseFunc that echoes the request ID found
+// in ctx in the response headers. Use it in servers.
+func ToHTTPResponse() httptransport.ResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter) {
+		if id, ok := FromContext(ctx); ok {
+			w.Header().Set(HTTPHeader, id)
+		}
+	}
+}
+
+// ToHTTPRequest returns an http RequestFunc that forwards the request ID
+// found in ctx in the request headers. If no request ID can be found, the
+// RequestFunc is a noop. Use it in clients.
+func ToHTTPRequest() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if id, ok := FromContext(ctx); ok {
+			r.Header.Set(HTTPHeader, id)
+		}
+		return ctx
+	}
+}
+
+// FromGRPCRequest returns a grpc RequestFunc that puts the request ID found
+// in the request metadata into the returned context. If the request has no
+// valid request ID, a new one is generated. A nil generator means NewID.
+// Use it in servers.
+func FromGRPCRequest(gen Generator) grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		var id string
+		if vals := (*md)[GRPCKey]; len(vals) > 0 {
+			id = vals[0]
+		}
+		return NewContext(ctx, acceptOrGenerate(id, gen))
+	}
+}
+
+// ToGRPCResponse returns a grpc ResponseFunc that echoes the request ID found
+// in ctx in the response header metadata. Use it in servers.
+func ToGRPCResponse() grpctransport.ResponseFunc {
+	return func(ctx context.Context, md *metadata.MD) {
+		if id, ok := FromContext(ctx); ok {
+			(*md)[GRPCKey] = []string{id}
+		}
+	}
+}
+
+// ToGRPCRequest returns a grpc RequestFunc that forwards the request ID found
+// in ctx in the request metadata. If no request ID can be found, the
+// RequestFunc is a noop. Use it in clients.
+func ToGRPCRequest() grpctransport.RequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if id, ok := FromContext(ctx); ok {
+			(*md)[GRPCKey] = []string{id}
+		}
+		return ctx
+	}
+}
+
+// acceptOrGenerate returns id if it's a valid request ID, and a new one
+// otherwise.
+func acceptOrGenerate(id string, gen Generator) string {
+	if valid(id) {
+		return id
+	}
+	if gen == nil {
+		gen = NewID
+	}
+	return gen()
+}
+
+// valid reports whether id is a non-empty, reasonably short string of
+// printable ASCII characters, which can be logged and forwarded safely.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package requestid_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/ctxlog"
+	"github.com/go-kit/kit/util/requestid"
+)
+
+func TestHTTPServer(t *testing.T) {
+	gen := func() string { return "generated" }
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{"", "generated"},
+		{"abc-123", "abc-123"},
+		{"has space", "generated"},
+		{strings.Repeat("x", 129), "generated"},
+	} {
+		r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+		r.Header.Set(requestid.HTTPHeader, tc.header)
+		ctx := requestid.FromHTTPRequest(gen)(context.Background(), r)
+		if have, _ := requestid.FromContext(ctx); tc.want != have {
+			t.Errorf("%q: want %q, have %q", tc.header, tc.want, have)
+		}
+
+		w := httptest.NewRecorder()
+		requestid.ToHTTPResponse()(ctx, w)
+		if have := w.Header().Get(requestid.HTTPHeader); tc.want != have {
+			t.Errorf("%q: want %q, have %q", tc.header, tc.want, have)
+		}
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://irrelevant", nil)
+	requestid.ToHTTPRequest()(requestid.NewContext(context.Background(), "abc"), r)
+	if want, have := "abc", r.Header.Get(requestid.HTTPHeader); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestGRPCRoundtrip(t *testing.T) {
+	md := metadata.MD{}
+	requestid.ToGRPCRequest()(requestid.NewContext(context.Background(), "abc"), &md)
+	ctx := requestid.FromGRPCRequest(nil)(context.Background(), &md)
+	if want, have := "abc", func() string { id, _ := requestid.FromContext(ctx); return id }(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	header := metadata.MD{}
+	requestid.ToGRPCResponse()(ctx, &header)
+	if want, have := []string{"abc"}, header[requestid.GRPCKey]; len(have) != 1 || want[0] != have[0] {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	a, b := requestid.NewID(), requestid.NewID()
+	if want, have := 32, len(a); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if a == b {
+		t.Errorf("want unique IDs, have %q twice", a)
+	}
+}
+
+func TestValuer(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := requestid.NewContext(context.Background(), "abc")
+	logger := log.NewContext(log.NewLogfmtLogger(&buf)).With("request_id", requestid.Valuer(ctx))
+	logger.Log("msg", "hello")
+	if want, have := "request_id=abc msg=hello\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestLogExtractor(t *testing.T) {
+	var buf bytes.Buffer
+	logger := ctxlog.New(log.NewLogfmtLogger(&buf), requestid.LogExtractor("request_id"))
+	logger.WithContext(requestid.NewContext(context.Background(), "abc")).Log("msg", "hello")
+	logger.WithContext(context.Background()).Log("msg", "hello")
+	if want, have := "request_id=abc msg=hello\nmsg=hello\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}